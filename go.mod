@@ -1,6 +1,6 @@
 module github.com/rwxrob/structs
 
-go 1.18
+go 1.23
 
 require github.com/rwxrob/json v0.7.0
 
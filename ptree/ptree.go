@@ -0,0 +1,310 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package ptree is a persistent (immutable) variant of the rooted node tree
+in package tree. Nodes are never changed once created. Instead, every
+update returns a new Root that shares every Node that was not on the
+path to the change with the one before it, so keeping every previous
+version of a large tree costs only the nodes that changed (plus those
+above them). Positions are paths of indexes (starting from 0) of each
+Node under the one above it (the same as tree.Edit). Use FromTree and
+E.Tree to convert to and from tree.E.
+*/
+package ptree
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+
+	json "github.com/rwxrob/json/pkg"
+	"github.com/rwxrob/structs/tree"
+	"github.com/rwxrob/structs/types"
+)
+
+// Node is an immutable Node with a type, value, and the nodes under it
+// (which may be shared with other trees). The zero value is an UNKNOWN
+// Node with a zero value and nothing under it.
+type Node[T any] struct {
+	t    int
+	v    T
+	kids []*Node[T]
+}
+
+// New returns a new Node with the nodes passed under it. The slice is
+// copied and the nodes are shared.
+func New[T any](t int, v T, kids ...*Node[T]) *Node[T] {
+	return &Node[T]{t, v, slices.Clone(kids)}
+}
+
+// T returns the type.
+func (n *Node[T]) T() int { return n.t }
+
+// V returns the value.
+func (n *Node[T]) V() T { return n.v }
+
+// Len returns the number of nodes directly under this one.
+func (n *Node[T]) Len() int { return len(n.kids) }
+
+// Nodes returns a new slice of the nodes directly under this one.
+func (n *Node[T]) Nodes() []*Node[T] { return slices.Clone(n.kids) }
+
+// At returns the Node at the path from this one (this one if empty) or
+// nil if there is none.
+func (n *Node[T]) At(path ...int) *Node[T] {
+	for _, i := range path {
+		if i < 0 || i >= len(n.kids) {
+			return nil
+		}
+		n = n.kids[i]
+	}
+	return n
+}
+
+// ------------------------------ updates -----------------------------
+
+// update returns a new Root with the Node at path replaced by the one
+// returned by f (which is passed the Node at path) after copying every
+// Node above it. An error is returned if there is no Root (nil).
+func (n *Node[T]) update(path []int, f func(c *Node[T]) (*Node[T], error)) (*Node[T], error) {
+	if n == nil {
+		return nil, fmt.Errorf("empty tree")
+	}
+	above := make([]*Node[T], len(path))
+	c := n
+	for d, i := range path {
+		if i < 0 || i >= len(c.kids) {
+			return nil, fmt.Errorf("invalid path: %v", path[:d+1])
+		}
+		above[d] = c
+		c = c.kids[i]
+	}
+	c, err := f(c)
+	if err != nil {
+		return nil, err
+	}
+	for d := len(path) - 1; d >= 0; d-- {
+		p := *above[d]
+		p.kids = slices.Clone(p.kids)
+		p.kids[path[d]] = c
+		c = &p
+	}
+	return c, nil
+}
+
+// Set returns a new Root with the value of the Node at path changed.
+func (n *Node[T]) Set(path []int, v T) (*Node[T], error) {
+	return n.update(path, func(c *Node[T]) (*Node[T], error) {
+		return &Node[T]{c.t, v, c.kids}, nil
+	})
+}
+
+// SetType returns a new Root with the type of the Node at path changed.
+func (n *Node[T]) SetType(path []int, t int) (*Node[T], error) {
+	return n.update(path, func(c *Node[T]) (*Node[T], error) {
+		return &Node[T]{t, c.v, c.kids}, nil
+	})
+}
+
+// Replace returns a new Root with the Node at path (and everything
+// under it) replaced by the one passed (an empty path replaces the Root
+// itself, even if there is none).
+func (n *Node[T]) Replace(path []int, with *Node[T]) (*Node[T], error) {
+	if len(path) == 0 {
+		return with, nil
+	}
+	return n.update(path, func(*Node[T]) (*Node[T], error) { return with, nil })
+}
+
+// Insert returns a new Root with the Node passed added at path (moving
+// any there already and after it to the right). The last index of the
+// path may be the number of nodes under the one above (to add it after
+// all of them).
+func (n *Node[T]) Insert(path []int, c *Node[T]) (*Node[T], error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot insert at Root")
+	}
+	i := path[len(path)-1]
+	return n.update(path[:len(path)-1], func(p *Node[T]) (*Node[T], error) {
+		if i < 0 || i > len(p.kids) {
+			return nil, fmt.Errorf("invalid path: %v", path)
+		}
+		return &Node[T]{p.t, p.v, slices.Insert(slices.Clone(p.kids), i, c)}, nil
+	})
+}
+
+// Append returns a new Root with the Node passed added under the one
+// at path (after all others).
+func (n *Node[T]) Append(path []int, c *Node[T]) (*Node[T], error) {
+	return n.update(path, func(p *Node[T]) (*Node[T], error) {
+		kids := make([]*Node[T], len(p.kids), len(p.kids)+1)
+		copy(kids, p.kids)
+		return &Node[T]{p.t, p.v, append(kids, c)}, nil
+	})
+}
+
+// Delete returns a new Root without the Node at path (and everything
+// under it).
+func (n *Node[T]) Delete(path []int) (*Node[T], error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot delete Root")
+	}
+	i := path[len(path)-1]
+	return n.update(path[:len(path)-1], func(p *Node[T]) (*Node[T], error) {
+		if i < 0 || i >= len(p.kids) {
+			return nil, fmt.Errorf("invalid path: %v", path)
+		}
+		return &Node[T]{p.t, p.v, slices.Delete(slices.Clone(p.kids), i, i+1)}, nil
+	})
+}
+
+// ---------------------------- converting ----------------------------
+
+// FromNode returns a new immutable copy of the tree.Node (and every
+// Node under it). Spans are not kept.
+func FromNode[T any](n *tree.Node[T]) *Node[T] {
+	return tree.Fold(n, func(c *tree.Node[T], kids []*Node[T]) *Node[T] {
+		return &Node[T]{c.T, c.V, kids}
+	})
+}
+
+// Node returns a new (mutable) tree.Node copy of this Node (and every
+// Node under it) attached to the given tree (which may be nil).
+func (n *Node[T]) Node(t *tree.E[T]) *tree.Node[T] {
+	type pair struct {
+		from *Node[T]
+		to   *tree.Node[T]
+	}
+	var root *tree.Node[T]
+	stack := []pair{{n, nil}}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		c := new(tree.Node[T])
+		c.T = cur.from.t
+		c.V = cur.from.v
+		c.Tree = t
+		if cur.to == nil {
+			root = c
+		} else {
+			cur.to.Append(c)
+		}
+		for i := len(cur.from.kids) - 1; i >= 0; i-- {
+			stack = append(stack, pair{cur.from.kids[i], c})
+		}
+	}
+	return root
+}
+
+// ---------------------------- marshaling ----------------------------
+
+// just for marshaling
+type jsnode[T any] struct {
+	T int
+	V T          `json:",omitempty"`
+	N []*Node[T] `json:",omitempty"`
+}
+
+// MarshalJSON implements encoding/json.Marshaler using the same compact
+// form as tree.Node (or null if nil, such as the Root of an empty E).
+func (n *Node[T]) MarshalJSON() ([]byte, error) {
+	if n == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(jsnode[T]{n.t, n.v, n.kids})
+}
+
+// String implements rwxrob/json.Stringer and fmt.Stringer.
+func (n *Node[T]) String() string {
+	byt, err := n.MarshalJSON()
+	if err != nil {
+		log.Print(err)
+	}
+	return string(byt)
+}
+
+// Print implements rwxrob/json.Printer.
+func (n *Node[T]) Print() { fmt.Println(n.String()) }
+
+// Log implements rwxrob/json.Logger.
+func (n *Node[T]) Log() { log.Print(n.String()) }
+
+// -------------------------------- E ---------------------------------
+
+// E is an immutable version of tree.E with Types and a Root. Every
+// update returns a new E with the same Types (which must not be
+// changed) and a new Root (see Node). The E passed to every update is
+// never changed so every version can be kept. Every update but Replace
+// of the Root (an empty path) returns an error if there is no Root.
+type E[T any] struct {
+	types.Types
+	Root *Node[T] `json:",omitempty"`
+}
+
+// FromTree returns a new immutable copy of the tree.E (including a copy
+// of its Types).
+func FromTree[T any](t *tree.E[T]) *E[T] {
+	e := new(E[T])
+	e.Types = cloneTypes(t.Types)
+	if t.Root != nil {
+		e.Root = FromNode(t.Root)
+	}
+	return e
+}
+
+// Tree returns a new (mutable) tree.E copy of this one (including
+// a copy of its Types).
+func (t *E[T]) Tree() *tree.E[T] {
+	e := new(tree.E[T])
+	e.Types = cloneTypes(t.Types)
+	if t.Root != nil {
+		e.Root = t.Root.Node(e)
+	}
+	return e
+}
+
+// cloneTypes returns a copy of the Types that shares nothing with it.
+func cloneTypes(t types.Types) types.Types {
+	return types.Types{Names: slices.Clone(t.Names), Map: maps.Clone(t.Map)}
+}
+
+func (t *E[T]) with(root *Node[T], err error) (*E[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	return &E[T]{t.Types, root}, nil
+}
+
+// Set returns a new version with the value of the Node at path changed.
+func (t *E[T]) Set(path []int, v T) (*E[T], error) {
+	return t.with(t.Root.Set(path, v))
+}
+
+// SetType returns a new version with the type of the Node at path
+// changed.
+func (t *E[T]) SetType(path []int, typ int) (*E[T], error) {
+	return t.with(t.Root.SetType(path, typ))
+}
+
+// Replace returns a new version with the Node at path replaced.
+func (t *E[T]) Replace(path []int, with *Node[T]) (*E[T], error) {
+	return t.with(t.Root.Replace(path, with))
+}
+
+// Insert returns a new version with the Node passed added at path.
+func (t *E[T]) Insert(path []int, c *Node[T]) (*E[T], error) {
+	return t.with(t.Root.Insert(path, c))
+}
+
+// Append returns a new version with the Node passed added under the one
+// at path (after all others).
+func (t *E[T]) Append(path []int, c *Node[T]) (*E[T], error) {
+	return t.with(t.Root.Append(path, c))
+}
+
+// Delete returns a new version without the Node at path.
+func (t *E[T]) Delete(path []int) (*E[T], error) {
+	return t.with(t.Root.Delete(path))
+}
@@ -0,0 +1,100 @@
+package ptree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/ptree"
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleNode() {
+	n := ptree.New(1, "",
+		ptree.New(2, "a"),
+		ptree.New(2, "b", ptree.New(3, "c")),
+	)
+	n.Print()
+	fmt.Println(n.Len(), n.At(1, 0).V(), n.At(1, 1))
+
+	// every version is kept and shares what did not change
+	m, _ := n.Set([]int{1, 0}, "C")
+	m.Print()
+	n.Print()
+	fmt.Println(m.At(0) == n.At(0), m.At(1) == n.At(1))
+
+	m, _ = m.Insert([]int{1}, ptree.New(2, "x"))
+	m, _ = m.Delete([]int{0})
+	m, _ = m.Append(nil, ptree.New(4, "z"))
+	m, _ = m.SetType([]int{2}, 2)
+	m.Print()
+	fmt.Println(m.At(1, 0) == n.At(1, 0))
+
+	_, err := m.Delete([]int{3})
+	fmt.Println(err)
+	_, err = m.Set([]int{0, 0, 0}, "")
+	fmt.Println(err)
+
+	// Output:
+	// {"T":1,"N":[{"T":2,"V":"a"},{"T":2,"V":"b","N":[{"T":3,"V":"c"}]}]}
+	// 2 c null
+	// {"T":1,"N":[{"T":2,"V":"a"},{"T":2,"V":"b","N":[{"T":3,"V":"C"}]}]}
+	// {"T":1,"N":[{"T":2,"V":"a"},{"T":2,"V":"b","N":[{"T":3,"V":"c"}]}]}
+	// true false
+	// {"T":1,"N":[{"T":2,"V":"x"},{"T":2,"V":"b","N":[{"T":3,"V":"C"}]},{"T":2,"V":"z"}]}
+	// false
+	// invalid path: [3]
+	// invalid path: [0 0]
+}
+
+func ExampleFromTree() {
+	t := tree.New[string]("Doc", "Para", "Word")
+	p := t.Root.Add(2, "")
+	p.Add(3, "hello")
+	p.Add(3, "there")
+
+	v1 := ptree.FromTree(t)
+	v2, _ := v1.Replace([]int{0, 1}, ptree.New(3, "world"))
+	v3, _ := v2.Append([]int{0}, ptree.New(3, "!"))
+
+	// the original is not affected
+	t.Root.Print()
+
+	for _, v := range []*ptree.E[string]{v1, v2, v3} {
+		s, _ := v.Tree().MarshalSexp()
+		fmt.Println(string(s))
+	}
+
+	back := v3.Tree()
+	fmt.Println(back.Root.Last().Last().Tree == back, back.Names)
+
+	// Output:
+	// {"T":1,"N":[{"T":2,"N":[{"T":3,"V":"hello"},{"T":3,"V":"there"}]}]}
+	// (Doc (Para (Word "hello") (Word "there")))
+	// (Doc (Para (Word "hello") (Word "world")))
+	// (Doc (Para (Word "hello") (Word "world") (Word "!")))
+	// true ["UNKNOWN","Doc","Para","Word"]
+}
+
+func ExampleE_empty() {
+	t := ptree.FromTree(new(tree.E[string]))
+	_, err := t.Append(nil, ptree.New(1, "x"))
+	fmt.Println(err)
+
+	v, err := t.Replace(nil, ptree.New(1, "x"))
+	fmt.Println(v.Root, err)
+
+	// the Types are copied both ways
+	m := tree.New[string]("Doc")
+	p := ptree.FromTree(m)
+	m.Types.Set("Para")
+	back := p.Tree()
+	back.Names[1] = "Changed"
+	fmt.Println(m.Names, p.Names, back.Names)
+
+	t.Root.Print()
+
+	// Output:
+	// empty tree
+	// {"T":1,"V":"x"} <nil>
+	// ["UNKNOWN","Para"] ["UNKNOWN","Doc"] ["UNKNOWN","Changed"]
+	// null
+}
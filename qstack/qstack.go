@@ -3,6 +3,7 @@ package qstack
 import (
 	"encoding/json"
 	"fmt"
+	"iter"
 	"log"
 )
 
@@ -39,9 +40,36 @@ func (s *QS[T]) Items() []T {
 	return items
 }
 
+// All returns an iterator over the index and value of every item from
+// the bottom (first) to the top (last) for use with range. Unlike Scan
+// any number of iterations may be in progress (or nested) at once.
+func (s *QS[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, cur := 0, s.bot; cur != nil; i, cur = i+1, cur.next {
+			if !yield(i, cur.V) {
+				return
+			}
+		}
+	}
+}
+
+// Backward is the same as All but from the top (last) to the bottom
+// (first) with the index still counting from the bottom.
+func (s *QS[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, cur := s.Len-1, s.top; cur != nil; i, cur = i-1, cur.prev {
+			if !yield(i, cur.V) {
+				return
+			}
+		}
+	}
+}
+
 // Scan advances to the next item each time it is called returning false
 // when there are no more items. Use Current to retrieve the value of
 // the current item.
+//
+// Deprecated: Scan allows only one iteration at a time. Use All instead.
 func (s *QS[T]) Scan() bool {
 
 	// first one
@@ -63,6 +91,8 @@ func (s *QS[T]) Scan() bool {
 }
 
 // Current returns the current value of Scan.
+//
+// Deprecated: Use All instead.
 func (s *QS[T]) Current() T {
 	var rv T
 	if s.cur == nil {
@@ -109,6 +139,7 @@ func (s *QS[T]) Pop() T {
 		s.Len--
 		it := s.top
 		s.top = nil
+		s.bot = nil
 		return it.V
 	default:
 		s.Len--
@@ -131,6 +162,7 @@ func (s *QS[T]) Shift() T {
 		s.Len--
 		it := s.bot
 		s.bot = nil
+		s.top = nil
 		return it.V
 	default:
 		s.Len--
@@ -140,7 +140,7 @@ func ExampleQS_Unshift() {
 	// 6
 }
 
-func ExampleQS_invalid_JSON_Types() {
+func ExampleQS_invalid_json_types() {
 	defer log.SetOutput(os.Stderr)
 	defer log.SetFlags(log.Flags())
 	log.SetOutput(os.Stdout)
@@ -190,3 +190,78 @@ func ExampleQS_Scan() {
 	// Output:
 	// foobarandone
 }
+
+func ExampleQS_All() {
+	s := qstack.New[any]()
+	s.Push("foo")
+	s.Push("bar")
+	s.Push("and")
+	s.Push("one")
+
+	for i, v := range s.All() {
+		fmt.Print(i, v, ";")
+	}
+	fmt.Println()
+
+	// nested and broken out of
+	for _, a := range s.All() {
+		for _, b := range s.All() {
+			if b == "bar" {
+				break
+			}
+			fmt.Print(a, b, ";")
+		}
+	}
+	fmt.Println()
+
+	// empty after popping everything
+	for s.Len > 0 {
+		s.Pop()
+	}
+	for i, v := range s.All() {
+		fmt.Print(i, v)
+	}
+
+	// Output:
+	// 0foo;1bar;2and;3one;
+	// foofoo;barfoo;andfoo;onefoo;
+}
+
+func ExampleQS_Backward() {
+	s := qstack.New[any]()
+	s.Push("foo")
+	s.Push("bar")
+	s.Push("and")
+	for i, v := range s.Backward() {
+		fmt.Print(i, v, ";")
+	}
+	fmt.Println()
+	for _, v := range s.Backward() {
+		fmt.Print(v, ";")
+		break
+	}
+	// Output:
+	// 2and;1bar;0foo;
+	// and;
+}
+
+func ExampleQS_empty() {
+	// removing the last item (from either end) leaves nothing behind
+	s := qstack.New[string]()
+	s.Push("a")
+	s.Pop()
+	for _, v := range s.All() {
+		fmt.Println("left", v)
+	}
+	s.Push("b")
+	s.Print()
+	s.Shift()
+	for _, v := range s.Backward() {
+		fmt.Println("left", v)
+	}
+	s.Push("c")
+	s.Print()
+	// Output:
+	// ["b"]
+	// ["c"]
+}
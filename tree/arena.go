@@ -0,0 +1,86 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+// DefaultChunk is the number of nodes in each chunk of an Arena when
+// none is given.
+var DefaultChunk = 1024
+
+// Arena allocates nodes for a tree from chunks (slices) of nodes
+// instead of one at a time which greatly reduces the work of the
+// garbage collector when building trees with millions of nodes (as
+// parsers do). Nodes from an Arena are normal in every way and may be
+// mixed with any others. The memory of every chunk is kept until
+// nothing refers to any Node in it (or the Arena is Released or Reset).
+// An Arena is not safe for concurrent use.
+type Arena[T any] struct {
+	Tree   *E[T]
+	size   int
+	chunks [][]Node[T]
+	free   []Node[T] // rest of the last chunk
+	count  int
+}
+
+// Arena returns a new Arena for this tree allocating chunks of the
+// given number of nodes (DefaultChunk if less than 1).
+func (t *E[T]) Arena(chunk int) *Arena[T] {
+	if chunk < 1 {
+		chunk = DefaultChunk
+	}
+	return &Arena[T]{Tree: t, size: chunk}
+}
+
+// Len returns the number of nodes allocated since the Arena was created
+// (or Reset).
+func (a *Arena[T]) Len() int { return a.count }
+
+// alloc returns the next unused Node (allocating a chunk if needed).
+func (a *Arena[T]) alloc() *Node[T] {
+	if len(a.free) == 0 {
+		if a.count/a.size < len(a.chunks) {
+			a.free = a.chunks[a.count/a.size] // reuse after Reset
+		} else {
+			a.free = make([]Node[T], a.size)
+			a.chunks = append(a.chunks, a.free)
+		}
+	}
+	n := &a.free[0]
+	a.free = a.free[1:]
+	a.count++
+	return n
+}
+
+// Node returns a new detached Node for the tree (see E.Node).
+func (a *Arena[T]) Node(typ int, val T) *Node[T] {
+	n := a.alloc()
+	n.T = typ
+	n.V = val
+	n.Tree = a.Tree
+	return n
+}
+
+// Add is the same as Node.Add but allocates from the Arena.
+func (a *Arena[T]) Add(under *Node[T], typ int, val T) *Node[T] {
+	n := a.Node(typ, val)
+	under.Append(n)
+	return n
+}
+
+// Reset zeroes every Node allocated so far so that the chunks can be
+// used again for a new tree. Every Node from the Arena (and anything
+// they are still attached to) must not be used after.
+func (a *Arena[T]) Reset() {
+	for _, c := range a.chunks {
+		clear(c)
+	}
+	a.free = nil
+	a.count = 0
+}
+
+// Release is the same as Reset but also lets go of every chunk for the
+// garbage collector to reclaim all at once.
+func (a *Arena[T]) Release() {
+	a.Reset()
+	a.chunks = nil
+}
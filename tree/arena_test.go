@@ -0,0 +1,82 @@
+package tree_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleArena() {
+	t := tree.New[string]("Doc", "Para", "Word")
+	a := t.Arena(2)
+	p := a.Add(t.Root, 2, "")
+	a.Add(p, 3, "hello")
+	a.Add(p, 3, "there")
+	p.Add(3, "!") // not from the arena
+	s, _ := t.MarshalSexp()
+	fmt.Println(string(s), a.Len())
+
+	// reuse the chunks for another tree
+	a.Reset()
+	u := tree.New[string]("Doc", "Para", "Word")
+	a.Tree = u
+	a.Add(u.Root, 2, "again")
+	s, _ = u.MarshalSexp()
+	fmt.Println(string(s), a.Len())
+
+	// Output:
+	// (Doc (Para (Word "hello") (Word "there") (Word "!"))) 3
+	// (Doc (Para "again")) 1
+}
+
+// build adds a wide and deep tree of about n nodes using add.
+func build(t *tree.E[string], n int, add func(*tree.Node[string], int, string) *tree.Node[string]) {
+	p := t.Root
+	for i := range n {
+		c := add(p, 2, "x")
+		if i%8 == 0 {
+			p = c
+		}
+	}
+}
+
+func BenchmarkNode_Add(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		t := tree.New[string]("Doc", "Node")
+		build(t, 100000, (*tree.Node[string]).Add)
+	}
+}
+
+func BenchmarkArena_Add(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		t := tree.New[string]("Doc", "Node")
+		build(t, 100000, t.Arena(0).Add)
+	}
+}
+
+func BenchmarkArena_Add_reset(b *testing.B) {
+	b.ReportAllocs()
+	t := tree.New[string]("Doc", "Node")
+	a := t.Arena(0)
+	for range b.N {
+		t.Init([]string{"Doc", "Node"})
+		a.Tree = t
+		build(t, 100000, a.Add)
+		a.Reset()
+	}
+}
+
+func BenchmarkNode_Append(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		t := tree.New[string]("Doc", "Node")
+		build(t, 100000, func(p *tree.Node[string], typ int, v string) *tree.Node[string] {
+			c := t.Node(typ, v)
+			p.Append(c)
+			return c
+		})
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	json "github.com/rwxrob/json/pkg"
+)
+
+// The binary encoding (see EncodeBinary) is much smaller and faster
+// than JSON and is meant for caching trees rather than sharing them. It
+// begins with a magic header followed by the Types (Names only, as
+// a count and then each length and name), a byte that is 1 if there is
+// a Root (0 for an empty tree, which has no nodes at all), and then
+// every Node in pre-order (document order) as its type (signed varint),
+// the number of nodes directly under it (unsigned varint), a flags byte
+// (bit 1 if it has a value, 2 if it has a Span), the value (length and
+// bytes from the Codec) and Span (six unsigned varints) if any.
+
+const binaryMagic = "TREE\x01"
+
+const (
+	binValue = 1 << iota
+	binSpan
+)
+
+// Codec converts values to and from bytes for EncodeBinary and
+// DecodeBinary. See DefaultCodec.
+type Codec[T any] interface {
+	MarshalValue(v T) ([]byte, error)
+	UnmarshalValue(buf []byte, v *T) error
+}
+
+// DefaultCodec returns the fastest Codec for T. Strings and []byte are
+// stored as is and every integer type as a varint. Anything else uses
+// JSONCodec.
+func DefaultCodec[T any]() Codec[T] {
+	var c any
+	switch any(*new(T)).(type) {
+	case string:
+		c = stringCodec{}
+	case []byte:
+		c = bytesCodec{}
+	case int:
+		c = intCodec[int]{}
+	case int8:
+		c = intCodec[int8]{}
+	case int16:
+		c = intCodec[int16]{}
+	case int32:
+		c = intCodec[int32]{}
+	case int64:
+		c = intCodec[int64]{}
+	case uint:
+		c = intCodec[uint]{}
+	case uint8:
+		c = intCodec[uint8]{}
+	case uint16:
+		c = intCodec[uint16]{}
+	case uint32:
+		c = intCodec[uint32]{}
+	case uint64:
+		c = intCodec[uint64]{}
+	default:
+		return JSONCodec[T]{}
+	}
+	return c.(Codec[T])
+}
+
+// JSONCodec is a Codec for any T that can be marshaled as JSON.
+type JSONCodec[T any] struct{}
+
+// MarshalValue implements Codec.
+func (JSONCodec[T]) MarshalValue(v T) ([]byte, error) { return json.Marshal(v) }
+
+// UnmarshalValue implements Codec.
+func (JSONCodec[T]) UnmarshalValue(buf []byte, v *T) error { return json.Unmarshal(buf, v) }
+
+type stringCodec struct{}
+
+func (stringCodec) MarshalValue(v string) ([]byte, error) { return []byte(v), nil }
+
+func (stringCodec) UnmarshalValue(buf []byte, v *string) error {
+	*v = string(buf)
+	return nil
+}
+
+type bytesCodec struct{}
+
+func (bytesCodec) MarshalValue(v []byte) ([]byte, error) { return v, nil }
+
+func (bytesCodec) UnmarshalValue(buf []byte, v *[]byte) error {
+	*v = buf
+	return nil
+}
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// int64 conversion wraps large uint64 values around and back unchanged
+type intCodec[I integer] struct{}
+
+func (intCodec[I]) MarshalValue(v I) ([]byte, error) {
+	return binary.AppendVarint(nil, int64(v)), nil
+}
+
+func (intCodec[I]) UnmarshalValue(buf []byte, v *I) error {
+	i, n := binary.Varint(buf)
+	if n <= 0 || n != len(buf) {
+		return fmt.Errorf("invalid varint value")
+	}
+	*v = I(i)
+	return nil
+}
+
+// ----------------------------- encoding -----------------------------
+
+// MarshalBinary implements encoding.BinaryMarshaler using EncodeBinary
+// with the DefaultCodec.
+func (s E[T]) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeBinary(buf, &s, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeBinary writes the tree in the binary encoding (see above) using
+// the Codec for values (DefaultCodec if nil). See DecodeBinary.
+func EncodeBinary[T any](w io.Writer, t *E[T], c Codec[T]) error {
+	if c == nil {
+		c = DefaultCodec[T]()
+	}
+	out := bufio.NewWriter(w)
+	var buf []byte
+	buf = append(buf, binaryMagic...)
+	buf = binary.AppendUvarint(buf, uint64(len(t.Names)))
+	for _, name := range t.Names {
+		buf = binary.AppendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+	}
+	if t.Root == nil {
+		buf = append(buf, 0)
+	} else {
+		buf = append(buf, 1)
+	}
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+	if t.Root == nil {
+		return out.Flush()
+	}
+
+	var err error
+	t.Root.WalkDeepPreFlow(func(n *Node[T]) Flow {
+		buf = binary.AppendVarint(buf[:0], int64(n.T))
+		var count uint64 // Count could be out of date
+		for c := n.first; c != nil; c = c.right {
+			count++
+		}
+		buf = binary.AppendUvarint(buf, count)
+		var flags byte
+		var v []byte
+		if hasValue(n.V) {
+			flags |= binValue
+			if v, err = c.MarshalValue(n.V); err != nil {
+				return Stop
+			}
+		}
+		if n.S != nil {
+			flags |= binSpan
+		}
+		buf = append(buf, flags)
+		if flags&binValue != 0 {
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+			buf = append(buf, v...)
+		}
+		if s := n.S; s != nil {
+			for _, i := range []int{
+				s.Beg.Off, s.Beg.Line, s.Beg.Col,
+				s.End.Off, s.End.Line, s.End.Col,
+			} {
+				buf = binary.AppendUvarint(buf, uint64(i))
+			}
+		}
+		if _, err = out.Write(buf); err != nil {
+			return Stop
+		}
+		return Continue
+	})
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// ----------------------------- decoding -----------------------------
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler using
+// DecodeBinary with the DefaultCodec replacing the Types and Root.
+func (s *E[T]) UnmarshalBinary(buf []byte) error {
+	t, err := DecodeBinary[T](bytes.NewReader(buf), nil)
+	if err != nil {
+		return err
+	}
+	s.Types = t.Types
+	s.Root = t.Root
+	if s.Root != nil {
+		s.Root.WalkDeepPre(func(n *Node[T]) { n.Tree = s })
+	}
+	return nil
+}
+
+// DecodeBinary reads a tree written by EncodeBinary using the Codec for
+// values (DefaultCodec if nil) rebuilding every link between the nodes
+// (as well as P, Count, and Tree). Nothing more than the tree is read
+// from r when it is an io.ByteReader.
+func DecodeBinary[T any](r io.Reader, c Codec[T]) (*E[T], error) {
+	if c == nil {
+		c = DefaultCodec[T]()
+	}
+	in, is := r.(binaryReader)
+	if !is {
+		in = bufio.NewReader(r)
+	}
+	d := &binaryDecoder{in: in}
+	t := new(E[T])
+
+	magic := d.bytes(uint64(len(binaryMagic)))
+	if d.err == nil && string(magic) != binaryMagic {
+		return nil, fmt.Errorf("not a binary tree")
+	}
+	var names []string
+	for i, n := uint64(0), d.uvarint(); i < n && d.err == nil; i++ {
+		names = append(names, string(d.bytes(d.uvarint())))
+	}
+	rooted := d.byte()
+	if d.err != nil {
+		return nil, d.unexpected()
+	}
+	if len(names) > 0 {
+		t.Types.Set(names[1:]...)
+		t.Names[0] = names[0]
+	}
+	switch rooted {
+	case 0:
+		return t, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("invalid binary tree")
+	}
+
+	// only the number of nodes still to come under each is kept
+	type open struct {
+		n    *Node[T]
+		left int
+	}
+	var stack []open
+	for {
+		typ := d.varint()
+		n := new(Node[T])
+		n.Tree = t
+		n.T = int(typ)
+		count := d.uvarint()
+		flags := d.byte()
+		if flags&binValue != 0 {
+			v := d.bytes(d.uvarint())
+			if d.err == nil {
+				if err := c.UnmarshalValue(v, &n.V); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if flags&binSpan != 0 {
+			var a [6]int
+			for i := range a {
+				a[i] = int(d.uvarint())
+			}
+			n.S = &Span{Pos{a[0], a[1], a[2]}, Pos{a[3], a[4], a[5]}}
+		}
+		if d.err != nil {
+			return nil, d.unexpected()
+		}
+
+		if stack == nil {
+			t.Root = n
+		} else {
+			top := &stack[len(stack)-1]
+			top.n.Append(n)
+			top.left--
+		}
+		stack = append(stack, open{n, int(count)})
+		for len(stack) > 0 && stack[len(stack)-1].left == 0 {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			return t, nil
+		}
+	}
+}
+
+type binaryReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// binaryDecoder keeps the first error so that reads can be chained
+// and checked once.
+type binaryDecoder struct {
+	in  binaryReader
+	err error
+}
+
+func (d *binaryDecoder) unexpected() error {
+	if d.err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return d.err
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var i uint64
+	i, d.err = binary.ReadUvarint(d.in)
+	return i
+}
+
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	var i int64
+	i, d.err = binary.ReadVarint(d.in)
+	return i
+}
+
+func (d *binaryDecoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	var b byte
+	b, d.err = d.in.ReadByte()
+	return b
+}
+
+// bytes reads exactly n bytes without trusting n to be reasonable.
+func (d *binaryDecoder) bytes(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(d.in, int64(n)))
+	switch {
+	case err != nil:
+		d.err = err
+	case uint64(len(buf)) != n:
+		d.err = io.EOF
+	}
+	return buf
+}
@@ -0,0 +1,74 @@
+package tree_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleEncodeBinary() {
+	t := grammar()
+	buf := new(bytes.Buffer)
+	if err := tree.EncodeBinary(buf, t, nil); err != nil {
+		fmt.Println(err)
+	}
+	js, _ := t.MarshalJSON()
+	fmt.Println(buf.Len() < len(js))
+
+	u, err := tree.DecodeBinary[any](buf, nil)
+	fmt.Println(err, u.Root.String() == t.Root.String(), u.Names)
+	fmt.Println(u.Root.Count, u.Root.Last().P == u.Root, u.Root.First().Tree == u)
+
+	// nothing more than each tree is read (even when empty)
+	buf.Reset()
+	tree.EncodeBinary(buf, new(tree.E[any]), nil)
+	t.Root.Count = 99 // out of date
+	tree.EncodeBinary(buf, t, nil)
+	r := bytes.NewReader(buf.Bytes())
+	empty, err := tree.DecodeBinary[any](r, nil)
+	fmt.Println(empty.Root == nil, err)
+	u, err = tree.DecodeBinary[any](r, nil)
+	fmt.Println(err, u.Root.String() == t.Root.String(), u.Root.Count, r.Len())
+
+	// Output:
+	// true
+	// <nil> true ["UNKNOWN","Grammar","Rule","Ident","Count"]
+	// 2 true true
+	// true <nil>
+	// <nil> true 2 0
+}
+
+func ExampleE_MarshalBinary() {
+	t := tree.New[int]("Sum", "Num")
+	t.Root.Add(2, -1)
+	t.Root.Add(2, 0)
+	t.Root.Add(2, 300).S = &tree.Span{End: tree.Pos{3, 1, 4}}
+
+	buf, _ := t.MarshalBinary()
+	fmt.Printf("%q\n", buf)
+
+	u := new(tree.E[int])
+	fmt.Println(u.UnmarshalBinary(buf))
+	u.Root.Print()
+	fmt.Println(u.Root.Last().S)
+
+	// values that are not the fast paths are JSON
+	v := tree.New[[]int]("List")
+	v.Root.V = []int{1, 2}
+	buf, _ = v.MarshalBinary()
+	w := new(tree.E[[]int])
+	fmt.Println(w.UnmarshalBinary(buf), w.Root.V)
+
+	fmt.Println(w.UnmarshalBinary(buf[:len(buf)-1]))
+	fmt.Println(w.UnmarshalBinary([]byte("nope!")))
+
+	// Output:
+	// "TREE\x01\x03\aUNKNOWN\x03Sum\x03Num\x01\x02\x03\x00\x04\x00\x01\x01\x01\x04\x00\x00\x04\x00\x03\x02\xd8\x04\x00\x00\x00\x03\x01\x04"
+	// <nil>
+	// {"T":1,"N":[{"T":2,"V":-1},{"T":2},{"T":2,"V":300,"S":[0,0,0,3,1,4]}]}
+	// 0:0-1:4
+	// <nil> [1 2]
+	// unexpected EOF
+	// not a binary tree
+}
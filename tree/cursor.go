@@ -0,0 +1,224 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/qstack"
+)
+
+// Cursor (sometimes called a zipper) points to a single Node within
+// a tree and can be moved from it to those next to it or change the tree
+// around it. Every change is recorded so that it can be undone (and
+// redone after that) in order. Making any new change discards what
+// could have been redone. Every change is made with Cut, Append, and
+// Take (so inserting before other nodes moves them as well). Changes
+// made to the tree other than through the Cursor (since the last change
+// through it) make Undo and Redo unpredictable.
+type Cursor[T any] struct {
+	Tree *E[T]
+	node *Node[T]
+	undo *qstack.QS[*change[T]]
+	redo *qstack.QS[*change[T]]
+}
+
+// change is a single reversible operation. Each is either a swap of the
+// type and value (set) or a move of n from one place to another where
+// p is the Node above and left is the one just before (nil if first).
+// A nil p is not under anything.
+type change[T any] struct {
+	set    bool
+	n      *Node[T]
+	t      int
+	v      T
+	p0, l0 *Node[T] // from
+	p1, l1 *Node[T] // to
+	at, to *Node[T] // cursor before and after
+}
+
+// Cursor returns a new Cursor at the Root, which must not be nil (see
+// Init).
+func (t *E[T]) Cursor() *Cursor[T] {
+	return &Cursor[T]{
+		Tree: t,
+		node: t.Root,
+		undo: qstack.New[*change[T]](),
+		redo: qstack.New[*change[T]](),
+	}
+}
+
+// Node returns the Node at the Cursor.
+func (c *Cursor[T]) Node() *Node[T] { return c.node }
+
+// ----------------------------- moving -------------------------------
+
+// MoveTo moves the Cursor to any Node (which should be in the tree).
+func (c *Cursor[T]) MoveTo(n *Node[T]) { c.node = n }
+
+// Up moves to the Node above and returns true or false if there is
+// none.
+func (c *Cursor[T]) Up() bool { return c.move(c.node.P) }
+
+// Down moves to the first Node under this one and returns true or
+// false if there is none.
+func (c *Cursor[T]) Down() bool { return c.move(c.node.first) }
+
+// Next moves to the Node after this one (right) and returns true or
+// false if there is none.
+func (c *Cursor[T]) Next() bool { return c.move(c.node.right) }
+
+// Prev moves to the Node before this one (left) and returns true or
+// false if there is none.
+func (c *Cursor[T]) Prev() bool { return c.move(c.node.left) }
+
+func (c *Cursor[T]) move(n *Node[T]) bool {
+	if n == nil {
+		return false
+	}
+	c.node = n
+	return true
+}
+
+// ----------------------------- editing ------------------------------
+
+// Set changes the value of the Node at the Cursor.
+func (c *Cursor[T]) Set(v T) {
+	c.do(&change[T]{set: true, n: c.node, t: c.node.T, v: v})
+}
+
+// SetType changes the type of the Node at the Cursor.
+func (c *Cursor[T]) SetType(t int) {
+	c.do(&change[T]{set: true, n: c.node, t: t, v: c.node.V})
+}
+
+// InsertBefore adds the Node (which is Cut first from wherever it was)
+// just before the one at the Cursor and moves the Cursor to it. The
+// Root cannot have any nodes next to it.
+func (c *Cursor[T]) InsertBefore(n *Node[T]) error {
+	if c.node.P == nil {
+		return fmt.Errorf("cannot insert next to Root")
+	}
+	return c.place(n, c.node.P, c.node.left)
+}
+
+// InsertAfter adds the Node (which is Cut first from wherever it was)
+// just after the one at the Cursor and moves the Cursor to it. The Root
+// cannot have any nodes next to it.
+func (c *Cursor[T]) InsertAfter(n *Node[T]) error {
+	if c.node.P == nil {
+		return fmt.Errorf("cannot insert next to Root")
+	}
+	return c.place(n, c.node.P, c.node)
+}
+
+// Append adds the Node (which is Cut first from wherever it was) under
+// the one at the Cursor after all others and moves the Cursor to it.
+func (c *Cursor[T]) Append(n *Node[T]) error {
+	return c.place(n, c.node, c.node.last)
+}
+
+// place returns an error if the Node is the one at the Cursor or above
+// it (which would be a cycle).
+func (c *Cursor[T]) place(n, p, left *Node[T]) error {
+	if c.node.within(n) {
+		return fmt.Errorf("cannot insert Node at Cursor or above it")
+	}
+	if left == n {
+		left = n.left
+	}
+	c.do(&change[T]{n: n, p0: n.P, l0: n.left, p1: p, l1: left, to: n})
+	return nil
+}
+
+// Delete cuts the Node at the Cursor from the tree and moves the Cursor
+// to the Node after it, or before it if there is none after, or above
+// it if it was the only one. The Root cannot be deleted.
+func (c *Cursor[T]) Delete() error {
+	n := c.node
+	if n.P == nil {
+		return fmt.Errorf("cannot delete Root")
+	}
+	to := n.right
+	if to == nil {
+		to = n.left
+	}
+	if to == nil {
+		to = n.P
+	}
+	c.do(&change[T]{n: n, p0: n.P, l0: n.left, to: to})
+	return nil
+}
+
+// do applies the change and records it for Undo.
+func (c *Cursor[T]) do(ch *change[T]) {
+	ch.at = c.node
+	if ch.to == nil {
+		ch.to = c.node
+	}
+	ch.apply(false)
+	c.node = ch.to
+	c.undo.Push(ch)
+	c.redo = qstack.New[*change[T]]()
+}
+
+// apply makes the change (or reverses it). Setting is its own reverse.
+// Moves Cut the Node and then (unless it is to be removed) Take every
+// Node after left to Append it before putting them back.
+func (ch *change[T]) apply(reverse bool) {
+	if ch.set {
+		t, v := ch.n.T, ch.n.V
+		ch.n.T, ch.n.V = ch.t, ch.v
+		ch.t, ch.v = t, v
+		return
+	}
+	p, left := ch.p1, ch.l1
+	if reverse {
+		p, left = ch.p0, ch.l0
+	}
+	ch.n.Cut()
+	if p == nil {
+		return
+	}
+	after := p.first
+	if left != nil {
+		after = left.right
+	}
+	rest := new(Node[T])
+	for after != nil {
+		next := after.right
+		rest.Append(after)
+		after = next
+	}
+	p.Append(ch.n)
+	p.Take(rest)
+}
+
+// ----------------------------- history ------------------------------
+
+// Undo reverses the last change (moving the Cursor back to where it was
+// before it) and returns true or false if there is nothing to undo.
+func (c *Cursor[T]) Undo() bool {
+	if c.undo.Len == 0 {
+		return false
+	}
+	ch := c.undo.Pop()
+	ch.apply(true)
+	c.node = ch.at
+	c.redo.Push(ch)
+	return true
+}
+
+// Redo makes the last change undone by Undo again and returns true or
+// false if there is nothing to redo.
+func (c *Cursor[T]) Redo() bool {
+	if c.redo.Len == 0 {
+		return false
+	}
+	ch := c.redo.Pop()
+	ch.apply(false)
+	c.node = ch.to
+	c.undo.Push(ch)
+	return true
+}
@@ -0,0 +1,105 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleCursor() {
+	t := tree.New[string]("Doc", "Para", "Word")
+	show := func() {
+		s, _ := t.MarshalSexp()
+		fmt.Println(string(s))
+	}
+
+	c := t.Cursor()
+	c.Append(t.Node(2, ""))
+	c.Append(t.Node(3, "hello"))
+	c.InsertAfter(t.Node(3, "world"))
+	show()
+
+	c.Prev()
+	c.Set("goodbye")
+	c.Up()
+	c.InsertAfter(t.Node(2, "the end"))
+	c.SetType(3)
+	show()
+
+	c.Prev()
+	c.Down()
+	c.Next()
+	c.Delete()
+	fmt.Println(c.Node().V)
+	show()
+
+	for c.Undo() {
+		show()
+	}
+	fmt.Println(c.Node() == t.Root)
+
+	c.Redo()
+	c.Redo()
+	c.Redo()
+	show()
+	fmt.Println(c.Node().V)
+
+	// a new change discards what could be redone
+	c.Set("hi")
+	fmt.Println(c.Redo())
+	show()
+
+	// Output:
+	// (Doc (Para (Word "hello") (Word "world")))
+	// (Doc (Para (Word "goodbye") (Word "world")) (Word "the end"))
+	// goodbye
+	// (Doc (Para (Word "goodbye")) (Word "the end"))
+	// (Doc (Para (Word "goodbye") (Word "world")) (Word "the end"))
+	// (Doc (Para (Word "goodbye") (Word "world")) (Para "the end"))
+	// (Doc (Para (Word "goodbye") (Word "world")))
+	// (Doc (Para (Word "hello") (Word "world")))
+	// (Doc (Para (Word "hello")))
+	// (Doc (Para))
+	// (Doc)
+	// true
+	// (Doc (Para (Word "hello") (Word "world")))
+	// world
+	// false
+	// (Doc (Para (Word "hello") (Word "hi")))
+}
+
+func ExampleCursor_move() {
+	t := tree.New[string]("Doc", "Para", "Word")
+	p := t.Root.Add(2, "")
+	p.Add(3, "a")
+	p.Add(3, "b")
+	q := t.Root.Add(2, "")
+	q.Add(3, "c")
+
+	// moving a Node that is already in the tree
+	c := t.Cursor()
+	c.MoveTo(q.First())
+	c.InsertBefore(p.First())
+	s, _ := t.MarshalSexp()
+	fmt.Println(string(s))
+
+	c.Undo()
+	s, _ = t.MarshalSexp()
+	fmt.Println(string(s))
+
+	fmt.Println(c.Up(), c.Up(), c.Up())
+	fmt.Println(c.Delete())
+
+	// nothing can be put under itself
+	c.MoveTo(q.First())
+	fmt.Println(c.Append(q))
+	fmt.Println(c.InsertAfter(q.First()))
+
+	// Output:
+	// (Doc (Para (Word "b")) (Para (Word "a") (Word "c")))
+	// (Doc (Para (Word "a") (Word "b")) (Para (Word "c")))
+	// true true false
+	// cannot delete Root
+	// cannot insert Node at Cursor or above it
+	// cannot insert Node at Cursor or above it
+}
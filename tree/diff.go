@@ -0,0 +1,338 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/rwxrob/structs/qstack"
+)
+
+// ------------------------------ Equal -------------------------------
+
+// Equal returns true if the other Node has the same type, value, and
+// nodes under it (recursively) in the same order. Values are compared
+// with reflect.DeepEqual. Nothing about the nodes above either one is
+// considered. See EqualFunc.
+func (n *Node[T]) Equal(o *Node[T]) bool {
+	return n.EqualFunc(o, deepEqual[T])
+}
+
+// EqualFunc is the same as Equal but uses the given function to
+// compare values.
+func (n *Node[T]) EqualFunc(o *Node[T], eq func(a, b T) bool) bool {
+	list := qstack.New[[2]*Node[T]]()
+	list.Push([2]*Node[T]{n, o})
+	for list.Len > 0 {
+		p := list.Pop()
+		a, b := p[0], p[1]
+		if a == nil || b == nil {
+			if a != b {
+				return false
+			}
+			continue
+		}
+		if a.T != b.T || a.Count != b.Count || !eq(a.V, b.V) {
+			return false
+		}
+		for x, y := a.first, b.first; x != nil || y != nil; {
+			list.Push([2]*Node[T]{x, y})
+			if x != nil {
+				x = x.right
+			}
+			if y != nil {
+				y = y.right
+			}
+		}
+	}
+	return true
+}
+
+func deepEqual[T any](a, b T) bool { return reflect.DeepEqual(a, b) }
+
+// ------------------------------- Diff -------------------------------
+
+// EditOp is the operation of a single Edit.
+type EditOp string
+
+const (
+	EditInsert EditOp = "insert" // add N at Path
+	EditDelete EditOp = "delete" // cut Node at Path
+	EditMove   EditOp = "move"   // cut Node at Path and add at To
+	EditType   EditOp = "type"   // change type of Node at Path to T
+	EditValue  EditOp = "value"  // change value of Node at Path to V
+)
+
+// Edit is a single change to a tree as produced by Diff and consumed by
+// Patch. Path (and To) are the positions (starting from 0) of each Node
+// under the one above it starting from the Node being patched (an empty
+// Path is that Node itself). Every Path is relative to the state of the
+// tree after all the edits before it have been applied. The To Path of
+// a move is where the Node ends up after being cut from Path.
+type Edit[T any] struct {
+	Op   EditOp
+	Path []int
+	To   []int    `json:",omitempty"`
+	T    int      `json:",omitempty"`
+	V    T        `json:",omitempty"`
+	N    *Node[T] `json:",omitempty"`
+}
+
+// Diff returns the list of edits (an edit script) that will change
+// this Node into the other when passed to Patch. Values are compared
+// with reflect.DeepEqual. See DiffFunc.
+func (n *Node[T]) Diff(to *Node[T]) []Edit[T] {
+	return n.DiffFunc(to, deepEqual[T])
+}
+
+// DiffFunc is the same as Diff but uses the given function to compare
+// values. The nodes under each pair of nodes are aligned by the longest
+// common sequence of their types. Those that remain in the old tree are
+// deleted unless one is equal to a Node that would otherwise be
+// inserted later in document order, in which case it is moved there
+// instead. Neither Node is changed.
+func (n *Node[T]) DiffFunc(to *Node[T], eq func(a, b T) bool) []Edit[T] {
+	var edits []Edit[T]
+	var pool []*Node[T]
+
+	// changes are made to a copy so paths are always current
+	w := n.Copy()
+
+	list := qstack.New[[2]*Node[T]]()
+	list.Push([2]*Node[T]{w, to})
+	for list.Len > 0 {
+		p := list.Pop()
+		x, y := p[0], p[1]
+
+		if x.T != y.T {
+			edits = append(edits, Edit[T]{Op: EditType, Path: x.ipath(), T: y.T})
+			x.T = y.T
+		}
+		if !eq(x.V, y.V) {
+			edits = append(edits, Edit[T]{Op: EditValue, Path: x.ipath(), V: y.V})
+			x.V = y.V
+		}
+
+		xs, ys := x.Nodes(), y.Nodes()
+		match := lcsTypes(xs, ys)
+		matched := map[*Node[T]]bool{}
+		for _, m := range match {
+			if m != nil {
+				matched[m] = true
+			}
+		}
+		for _, c := range xs {
+			if !matched[c] {
+				pool = append(pool, c)
+			}
+		}
+
+		var prev *Node[T]
+		var pairs [][2]*Node[T]
+		for j, yc := range ys {
+			if xc := match[j]; xc != nil {
+				pairs = append(pairs, [2]*Node[T]{xc, yc})
+				prev = xc
+				continue
+			}
+
+			// move an equal one if one was left over
+			var moved *Node[T]
+			for i, c := range pool {
+				if c.EqualFunc(yc, eq) {
+					moved = c
+					pool = append(pool[:i], pool[i+1:]...)
+					break
+				}
+			}
+			if moved != nil {
+				from := moved.ipath()
+				place(x, prev, moved)
+				edits = append(edits, Edit[T]{Op: EditMove, Path: from, To: moved.ipath()})
+				prev = moved
+				continue
+			}
+
+			c := yc.Copy()
+			place(x, prev, c)
+			edits = append(edits, Edit[T]{Op: EditInsert, Path: c.ipath(), N: yc.Copy()})
+			prev = c
+		}
+
+		for i := len(pairs) - 1; i >= 0; i-- {
+			list.Push(pairs[i])
+		}
+	}
+
+	for _, c := range pool {
+		edits = append(edits, Edit[T]{Op: EditDelete, Path: c.ipath()})
+		c.Cut()
+	}
+
+	return edits
+}
+
+// place puts c under x just after prev (or first if prev is nil).
+func place[T any](x, prev, c *Node[T]) {
+	if prev == nil {
+		x.Prepend(c)
+		return
+	}
+	prev.InsertAfter(c)
+}
+
+// lcsTypes returns a slice the same length as ys with the Node from xs
+// matched to each (or nil) by the longest common sequence of types.
+func lcsTypes[T any](xs, ys []*Node[T]) []*Node[T] {
+	m, n := len(xs), len(ys)
+	tab := make([][]int, m+1)
+	for i := range tab {
+		tab[i] = make([]int, n+1)
+	}
+	for i := m - 1; i >= 0; i-- {
+		for j := n - 1; j >= 0; j-- {
+			switch {
+			case xs[i].T == ys[j].T:
+				tab[i][j] = tab[i+1][j+1] + 1
+			case tab[i+1][j] >= tab[i][j+1]:
+				tab[i][j] = tab[i+1][j]
+			default:
+				tab[i][j] = tab[i][j+1]
+			}
+		}
+	}
+	match := make([]*Node[T], n)
+	for i, j := 0, 0; i < m && j < n; {
+		switch {
+		case xs[i].T == ys[j].T:
+			match[j] = xs[i]
+			i++
+			j++
+		case tab[i+1][j] >= tab[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
+
+// ipath returns the positions of this Node and every one above it
+// under the one above each (see Edit).
+func (n *Node[T]) ipath() []int {
+	path := []int{}
+	for c := n; c.P != nil; c = c.P {
+		path = append([]int{c.Index()}, path...)
+	}
+	return path
+}
+
+// at returns the Node at the given path (see Edit) or nil.
+func (n *Node[T]) at(path []int) *Node[T] {
+	cur := n
+	for _, i := range path {
+		if i < 0 {
+			return nil
+		}
+		c := cur.first
+		for ; c != nil && i > 0; i-- {
+			c = c.right
+		}
+		if c == nil {
+			return nil
+		}
+		cur = c
+	}
+	return cur
+}
+
+// ------------------------------ Patch -------------------------------
+
+// Patch applies the edits (usually from Diff) to this Node in order
+// returning an error (and leaving the edits before it applied) at the
+// first one that cannot be applied. Inserted nodes are copies and are
+// attached to the same Tree as this Node. A Node that cannot be moved
+// is left where it was.
+func (n *Node[T]) Patch(edits []Edit[T]) error {
+	for i, e := range edits {
+		x := n.at(e.Path)
+		if x == nil && e.Op != EditInsert {
+			return fmt.Errorf("edit %v: invalid path: %v", i, e.Path)
+		}
+		switch e.Op {
+
+		case EditType:
+			x.T = e.T
+
+		case EditValue:
+			x.V = e.V
+
+		case EditDelete:
+			if x == n {
+				return fmt.Errorf("edit %v: cannot delete patched node", i)
+			}
+			x.Cut()
+
+		case EditInsert:
+			if e.N == nil || len(e.Path) == 0 {
+				return fmt.Errorf("edit %v: invalid insert", i)
+			}
+			c := e.N.Copy()
+			for u := range c.All() {
+				u.Tree = n.Tree
+			}
+			if err := n.insertAt(e.Path, c); err != nil {
+				return fmt.Errorf("edit %v: %v", i, err)
+			}
+
+		case EditMove:
+			if x == n || len(e.To) == 0 {
+				return fmt.Errorf("edit %v: invalid move", i)
+			}
+			p, left := x.P, x.left
+			x.Cut()
+			err := n.insertAt(e.To, x)
+			if err == nil && x.P == nil {
+				err = fmt.Errorf("cannot move under itself: %v", e.To)
+			}
+			if err != nil {
+				if left != nil {
+					left.InsertAfter(x)
+				} else {
+					p.Prepend(x)
+				}
+				return fmt.Errorf("edit %v: %v", i, err)
+			}
+
+		default:
+			return fmt.Errorf("edit %v: unknown op: %q", i, e.Op)
+		}
+	}
+	return nil
+}
+
+// insertAt puts c at the (non-empty) path.
+func (n *Node[T]) insertAt(path []int, c *Node[T]) error {
+	last := len(path) - 1
+	parent := n.at(path[:last])
+	if parent == nil {
+		return fmt.Errorf("invalid path: %v", path)
+	}
+	if path[last] == 0 {
+		parent.Prepend(c)
+		return nil
+	}
+	prev := parent.at(path[last:])
+	if prev != nil {
+		prev = prev.left
+	} else if path[last] == parent.Count {
+		prev = parent.last
+	}
+	if prev == nil {
+		return fmt.Errorf("invalid path: %v", path)
+	}
+	prev.InsertAfter(c)
+	return nil
+}
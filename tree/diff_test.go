@@ -0,0 +1,166 @@
+package tree_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleNode_Equal() {
+	a := new(tree.Node[any])
+	a.Add(1, "one").Add(11, "eleven")
+	a.Add(2, "two")
+
+	b := a.Copy()
+	fmt.Println(a.Equal(b))
+
+	b.Last().V = "TWO"
+	fmt.Println(a.Equal(b))
+
+	b.Last().V = "two"
+	b.Add(3, nil)
+	fmt.Println(a.Equal(b))
+
+	// pluggable comparison
+	c := a.Copy()
+	c.Last().V = "TWO"
+	fold := func(x, y any) bool {
+		return strings.EqualFold(fmt.Sprint(x), fmt.Sprint(y))
+	}
+	fmt.Println(a.EqualFunc(c, fold))
+
+	// Output:
+	// true
+	// false
+	// false
+	// true
+}
+
+func ExampleNode_Diff() {
+	a := new(tree.Node[any])
+	a.Add(1, "one")
+	a.Add(2, "two").Add(22, "deep")
+	a.Add(3, "three")
+
+	b := new(tree.Node[any])
+	b.Add(1, "ONE")
+	b.Add(4, "four")
+	b.Add(2, "two").Add(22, "deep")
+
+	edits := a.Diff(b)
+	for _, e := range edits {
+		buf, _ := json.Marshal(e)
+		fmt.Println(string(buf))
+	}
+
+	if err := a.Patch(edits); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(a.Equal(b))
+
+	// Output:
+	// {"Op":"insert","Path":[1],"N":{"T":4,"V":"four"}}
+	// {"Op":"value","Path":[0],"V":"ONE"}
+	// {"Op":"delete","Path":[3]}
+	// true
+}
+
+func ExampleNode_Diff_move() {
+	a := new(tree.Node[any])
+	x := a.Add(1, nil)
+	x.Add(11, "moving").Add(111, "along")
+	x.Add(12, "stays")
+	a.Add(2, nil)
+
+	b := new(tree.Node[any])
+	b.Add(1, nil).Add(12, "stays")
+	y := b.Add(2, nil)
+	y.Add(11, "moving").Add(111, "along")
+
+	edits := a.Diff(b)
+	buf, _ := json.Marshal(edits)
+	fmt.Println(string(buf))
+
+	a.Patch(edits)
+	fmt.Println(a.Equal(b))
+
+	// Output:
+	// [{"Op":"move","Path":[0,0],"To":[1,0]}]
+	// true
+}
+
+func ExampleNode_Patch() {
+	// edits can be stored and loaded as JSON
+	script := `[
+	  {"Op":"type","Path":[],"T":9},
+	  {"Op":"insert","Path":[0],"N":{"T":1,"N":[{"T":11,"V":"new"}]}},
+	  {"Op":"move","Path":[1],"To":[0,1]},
+	  {"Op":"value","Path":[0,1],"V":"moved"}
+	]`
+	var edits []tree.Edit[any]
+	if err := json.Unmarshal([]byte(script), &edits); err != nil {
+		fmt.Println(err)
+	}
+
+	n := new(tree.Node[any])
+	n.Add(2, "old")
+	if err := n.Patch(edits); err != nil {
+		fmt.Println(err)
+	}
+	n.Print()
+	fmt.Println(n.Count, n.First().Count, n.First().Last().P == n.First())
+
+	// bad edits stop the patch
+	err := n.Patch([]tree.Edit[any]{{Op: tree.EditDelete, Path: []int{5}}})
+	fmt.Println(err)
+	err = n.Patch([]tree.Edit[any]{{Op: tree.EditDelete}})
+	fmt.Println(err)
+	err = n.Patch([]tree.Edit[any]{{Op: tree.EditMove, Path: []int{0}, To: []int{0, 0}}})
+	fmt.Println(err)
+	n.Print() // not lost
+
+	// Output:
+	// {"T":9,"N":[{"T":1,"N":[{"T":11,"V":"new"},{"T":2,"V":"moved"}]}]}
+	// 1 2 true
+	// edit 0: invalid path: [5]
+	// edit 0: cannot delete patched node
+	// edit 0: invalid path: [0 0]
+	// {"T":9,"N":[{"T":1,"N":[{"T":11,"V":"new"},{"T":2,"V":"moved"}]}]}
+}
+
+func ExampleNode_Diff_round_trip() {
+	parse := func(s string) *tree.Node[any] {
+		n := new(tree.Node[any])
+		if err := n.UnmarshalJSON([]byte(s)); err != nil {
+			fmt.Println(err)
+		}
+		return n
+	}
+	pairs := [][2]string{
+		{`{"T":1}`, `{"T":2,"V":"x"}`},
+		{`{"T":1,"N":[{"T":2},{"T":3},{"T":4}]}`, `{"T":1}`},
+		{`{"T":1}`, `{"T":1,"N":[{"T":2},{"T":3,"N":[{"T":4}]}]}`},
+		{`{"T":1,"N":[{"T":2,"V":"a"},{"T":3,"V":"b"}]}`, `{"T":1,"N":[{"T":3,"V":"b"},{"T":2,"V":"a"}]}`},
+		{`{"T":1,"N":[{"T":2,"N":[{"T":5,"V":"x"}]},{"T":3}]}`, `{"T":1,"N":[{"T":3,"N":[{"T":5,"V":"x"}]},{"T":2}]}`},
+		{`{"T":1,"N":[{"T":2},{"T":2},{"T":2}]}`, `{"T":1,"N":[{"T":3},{"T":2,"V":1},{"T":4},{"T":2},{"T":5}]}`},
+	}
+	for _, p := range pairs {
+		a, b := parse(p[0]), parse(p[1])
+		edits := a.Diff(b)
+		buf, _ := json.Marshal(edits)
+		var loaded []tree.Edit[any]
+		json.Unmarshal(buf, &loaded)
+		c := a.Copy()
+		err := c.Patch(loaded)
+		fmt.Println(len(edits), err, c.Equal(b), a.String() == p[0])
+	}
+	// Output:
+	// 2 <nil> true true
+	// 3 <nil> true true
+	// 2 <nil> true true
+	// 1 <nil> true true
+	// 3 <nil> true true
+	// 5 <nil> true true
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+// Fold computes a result for every Node (bottom-up) by passing it and
+// the results of the nodes directly under it (in order) to the
+// function and returns the result for the Node passed. This is
+// sometimes called a catamorphism and is useful for evaluating
+// expressions, computing sizes and hashes, or converting to other
+// structures. It walks the tree with WalkDeepFlow (see WalkDeep).
+func Fold[T, R any](n *Node[T], f func(n *Node[T], kids []R) R) R {
+	stack := [][]R{nil}
+	enter := func(c *Node[T], _ int) Flow {
+		stack = append(stack, nil)
+		return Continue
+	}
+	leave := func(c *Node[T], _ int) Flow {
+		kids := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stack[len(stack)-1] = append(stack[len(stack)-1], f(c, kids))
+		return Continue
+	}
+	n.WalkDeepFlow(enter, leave)
+	return stack[0][0]
+}
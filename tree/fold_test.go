@@ -0,0 +1,73 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleFold() {
+	// (1 + 2 * 3) - 10 / 5
+	t := tree.New[int]("Sub", "Add", "Mul", "Div", "Num")
+	add := t.Root.Add(2, 0)
+	add.Add(5, 1)
+	mul := add.Add(3, 0)
+	mul.Add(5, 2)
+	mul.Add(5, 3)
+	div := t.Root.Add(4, 0)
+	div.Add(5, 10)
+	div.Add(5, 5)
+
+	eval := func(n *tree.Node[int], k []int) int {
+		switch t.Names[n.T] {
+		case "Add":
+			return k[0] + k[1]
+		case "Sub":
+			return k[0] - k[1]
+		case "Mul":
+			return k[0] * k[1]
+		case "Div":
+			return k[0] / k[1]
+		}
+		return n.V
+	}
+	fmt.Println(tree.Fold(t.Root, eval))
+	fmt.Println(tree.Fold(mul, eval))
+
+	show := func(n *tree.Node[int], k []string) string {
+		switch t.Names[n.T] {
+		case "Add":
+			return "(" + k[0] + " + " + k[1] + ")"
+		case "Sub":
+			return k[0] + " - " + k[1]
+		case "Mul":
+			return k[0] + " * " + k[1]
+		case "Div":
+			return k[0] + " / " + k[1]
+		}
+		return fmt.Sprint(n.V)
+	}
+	fmt.Println(tree.Fold(t.Root, show))
+
+	// Output:
+	// 5
+	// 6
+	// (1 + 2 * 3) - 10 / 5
+}
+
+func ExampleFold_deep() {
+	t := tree.New[int]("Nest")
+	n := t.Root
+	for range 100000 {
+		n = n.Add(1, 1)
+	}
+	depth := tree.Fold(t.Root, func(n *tree.Node[int], k []int) int {
+		if len(k) == 0 {
+			return 0
+		}
+		return k[0] + 1
+	})
+	fmt.Println(depth)
+	// Output:
+	// 100000
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphOpts are the options for DOT and Mermaid. The zero value (or
+// nil) draws every Node with its type name and every value as its own
+// node connected with a dashed line.
+type GraphOpts struct {
+
+	// Styles contains the styling for each type name (or integer as
+	// a string when there is no name). For DOT these are attributes (ex:
+	// "shape=box, color=red") and for Mermaid style properties (ex:
+	// "fill:#f9f,stroke:#333").
+	Styles map[string]string
+
+	// Collapse draws the value of every leaf Node inside the same box as
+	// its type name instead of as its own node.
+	Collapse bool
+}
+
+// DOT returns the Root as Graphviz DOT text (see Node.DOT).
+func (t *E[T]) DOT(o *GraphOpts) string {
+	if t.Root == nil {
+		return ""
+	}
+	return t.Root.DOT(o)
+}
+
+// Mermaid returns the Root as Mermaid flowchart text (see
+// Node.Mermaid).
+func (t *E[T]) Mermaid(o *GraphOpts) string {
+	if t.Root == nil {
+		return ""
+	}
+	return t.Root.Mermaid(o)
+}
+
+// DOT returns this Node and every Node under it as a Graphviz DOT
+// digraph suitable for rendering with dot(1). Nodes are identified by
+// their preorder position (n0, n1, ...) and labeled with the type name
+// from the Tree (or integer). Values are shown as they are by Render.
+func (n *Node[T]) DOT(o *GraphOpts) string {
+	if o == nil {
+		o = new(GraphOpts)
+	}
+	buf := new(strings.Builder)
+	buf.WriteString("digraph {\n")
+	ids := map[*Node[T]]int{}
+	for c := range n.All() {
+		id := len(ids)
+		ids[c] = id
+		label := c.name()
+		value := hasValue(c.V)
+		if value && o.Collapse && c.first == nil {
+			label += "\n" + renderValue(c.V, 0, "", false)
+			value = false
+		}
+		fmt.Fprintf(buf, "  n%v [label=\"%v\"", id, dotEscape(label))
+		if s, has := o.Styles[c.name()]; has {
+			buf.WriteString(", " + s)
+		}
+		buf.WriteString("];\n")
+		if c != n {
+			fmt.Fprintf(buf, "  n%v -> n%v;\n", ids[c.P], id)
+		}
+		if value {
+			v := dotEscape(renderValue(c.V, 0, "", false))
+			fmt.Fprintf(buf, "  v%v [label=\"%v\", shape=plaintext];\n", id, v)
+			fmt.Fprintf(buf, "  n%v -> v%v [style=dashed, arrowhead=none];\n", id, id)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid returns this Node and every Node under it as a Mermaid
+// flowchart (top down) suitable for embedding in Markdown. Nodes are
+// identified and labeled the same as DOT. Styles are added as a class
+// for each type (t0, t1, ...).
+func (n *Node[T]) Mermaid(o *GraphOpts) string {
+	if o == nil {
+		o = new(GraphOpts)
+	}
+	buf := new(strings.Builder)
+	buf.WriteString("flowchart TD\n")
+	ids := map[*Node[T]]int{}
+	classes := map[int][]string{}
+	names := map[int]string{}
+	for c := range n.All() {
+		id := len(ids)
+		ids[c] = id
+		label := mermaidEscape(c.name())
+		value := hasValue(c.V)
+		if value && o.Collapse && c.first == nil {
+			label += "<br>" + mermaidEscape(renderValue(c.V, 0, "", false))
+			value = false
+		}
+		fmt.Fprintf(buf, "  n%v[\"%v\"]\n", id, label)
+		if c != n {
+			fmt.Fprintf(buf, "  n%v --> n%v\n", ids[c.P], id)
+		}
+		if value {
+			v := mermaidEscape(renderValue(c.V, 0, "", false))
+			fmt.Fprintf(buf, "  v%v([\"%v\"])\n", id, v)
+			fmt.Fprintf(buf, "  n%v -.- v%v\n", id, id)
+		}
+		if _, has := o.Styles[c.name()]; has {
+			classes[c.T] = append(classes[c.T], fmt.Sprintf("n%v", id))
+			names[c.T] = c.name()
+		}
+	}
+	typs := make([]int, 0, len(classes))
+	for t := range classes {
+		typs = append(typs, t)
+	}
+	sort.Ints(typs)
+	for _, t := range typs {
+		fmt.Fprintf(buf, "  classDef t%v %v\n", t, o.Styles[names[t]])
+		fmt.Fprintf(buf, "  class %v t%v\n", strings.Join(classes[t], ","), t)
+	}
+	return buf.String()
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`,
+	).Replace(s)
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(
+		`#`, `#35;`, `"`, `#quot;`, `<`, `#lt;`, `>`, `#gt;`, "\n", `<br>`,
+	).Replace(s)
+}
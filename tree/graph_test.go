@@ -0,0 +1,109 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func expr() *tree.E[any] {
+	t := tree.New[any]("Expr", "Op", "Num")
+	op := t.Root.Add(2, "+")
+	op.Add(3, 1)
+	op.Add(3, `say "hi"`)
+	return t
+}
+
+func ExampleE_DOT() {
+	fmt.Print(expr().DOT(nil))
+	// Output:
+	// digraph {
+	//   n0 [label="Expr"];
+	//   n1 [label="Op"];
+	//   n0 -> n1;
+	//   v1 [label="\"+\"", shape=plaintext];
+	//   n1 -> v1 [style=dashed, arrowhead=none];
+	//   n2 [label="Num"];
+	//   n1 -> n2;
+	//   v2 [label="1", shape=plaintext];
+	//   n2 -> v2 [style=dashed, arrowhead=none];
+	//   n3 [label="Num"];
+	//   n1 -> n3;
+	//   v3 [label="\"say \\\"hi\\\"\"", shape=plaintext];
+	//   n3 -> v3 [style=dashed, arrowhead=none];
+	// }
+}
+
+func ExampleE_DOT_options() {
+	o := &tree.GraphOpts{
+		Styles:   map[string]string{"Num": "shape=box, color=blue"},
+		Collapse: true,
+	}
+	fmt.Print(expr().DOT(o))
+	// Output:
+	// digraph {
+	//   n0 [label="Expr"];
+	//   n1 [label="Op"];
+	//   n0 -> n1;
+	//   v1 [label="\"+\"", shape=plaintext];
+	//   n1 -> v1 [style=dashed, arrowhead=none];
+	//   n2 [label="Num\n1", shape=box, color=blue];
+	//   n1 -> n2;
+	//   n3 [label="Num\n\"say \\\"hi\\\"\"", shape=box, color=blue];
+	//   n1 -> n3;
+	// }
+}
+
+func ExampleE_Mermaid() {
+	fmt.Print(expr().Mermaid(nil))
+	// Output:
+	// flowchart TD
+	//   n0["Expr"]
+	//   n1["Op"]
+	//   n0 --> n1
+	//   v1(["#quot;+#quot;"])
+	//   n1 -.- v1
+	//   n2["Num"]
+	//   n1 --> n2
+	//   v2(["1"])
+	//   n2 -.- v2
+	//   n3["Num"]
+	//   n1 --> n3
+	//   v3(["#quot;say \#quot;hi\#quot;#quot;"])
+	//   n3 -.- v3
+}
+
+func ExampleE_Mermaid_options() {
+	o := &tree.GraphOpts{
+		Styles:   map[string]string{"Num": "fill:#f9f", "Expr": "stroke:#333"},
+		Collapse: true,
+	}
+	fmt.Print(expr().Mermaid(o))
+	// Output:
+	// flowchart TD
+	//   n0["Expr"]
+	//   n1["Op"]
+	//   n0 --> n1
+	//   v1(["#quot;+#quot;"])
+	//   n1 -.- v1
+	//   n2["Num<br>1"]
+	//   n1 --> n2
+	//   n3["Num<br>#quot;say \#quot;hi\#quot;#quot;"]
+	//   n1 --> n3
+	//   classDef t1 stroke:#333
+	//   class n0 t1
+	//   classDef t3 fill:#f9f
+	//   class n2,n3 t3
+}
+
+func ExampleNode_DOT() {
+	n := new(tree.Node[string])
+	n.Add(1, "a\nb")
+	fmt.Print(n.DOT(&tree.GraphOpts{Collapse: true}))
+	// Output:
+	// digraph {
+	//   n0 [label="0"];
+	//   n1 [label="1\n\"a\\nb\""];
+	//   n0 -> n1;
+	// }
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"bufio"
+	std "encoding/json"
+	"fmt"
+	"io"
+
+	json "github.com/rwxrob/json/pkg"
+)
+
+// JSONTypes are the type names (in order) of every tree returned by
+// FromJSON. See the JSON* constants.
+var JSONTypes = []string{
+	"Object", "Array", "Member", "String", "Number", "Bool", "Null",
+}
+
+const (
+	JSONObject = iota + 1 // {} with only JSONMember nodes under it
+	JSONArray             // [] with any other nodes under it
+	JSONMember            // V is key (string) with one node under it
+	JSONString            // V is string
+	JSONNumber            // V is encoding/json.Number (string)
+	JSONBool              // V is bool
+	JSONNull              // V is nil
+)
+
+// FromJSON reads any single JSON value (object, array, string, number,
+// true, false, or null) and returns it as a new tree with the
+// JSONTypes. Unlike UnmarshalJSON the input can be any JSON and need
+// not be the compact Node format. The Root is the top value (which may
+// not be a JSONObject). The order of object keys is preserved as the
+// order of the JSONMember nodes. Numbers are kept as json.Number
+// strings so that none of their precision is lost. The input is read
+// one token at a time keeping track of where it is only with P, so
+// values may be nested as deeply as the input allows.
+func FromJSON(r io.Reader) (*E[any], error) {
+	t := New[any](JSONTypes...)
+	dec := std.NewDecoder(r)
+	dec.UseNumber()
+
+	var cur *Node[any]
+	for done := false; !done; {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return t, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return t, err
+		}
+
+		// keys are the only strings directly under objects
+		if cur != nil && cur.T == JSONObject {
+			if key, is := tok.(string); is {
+				cur = cur.Add(JSONMember, key)
+				continue
+			}
+		}
+
+		var n *Node[any]
+		switch v := tok.(type) {
+		case std.Delim:
+			switch v {
+			case '{':
+				n = t.Node(JSONObject, nil)
+			case '[':
+				n = t.Node(JSONArray, nil)
+			default:
+				done = cur.P == nil
+				cur = closeJSON(cur.P)
+				continue
+			}
+		case string:
+			n = t.Node(JSONString, v)
+		case std.Number:
+			n = t.Node(JSONNumber, v)
+		case bool:
+			n = t.Node(JSONBool, v)
+		case nil:
+			n = t.Node(JSONNull, nil)
+		}
+
+		if cur == nil {
+			t.Root.T = n.T
+			t.Root.V = n.V
+			n = t.Root
+		} else {
+			cur.Append(n)
+		}
+		switch {
+		case n.T == JSONObject || n.T == JSONArray:
+			cur = n
+		case cur == nil:
+			done = true
+		default:
+			cur = closeJSON(cur)
+		}
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return t, fmt.Errorf("unexpected data after JSON value")
+	}
+	return t, nil
+}
+
+// closeJSON returns the Node to add the next value under after
+// a value has been added under cur.
+func closeJSON(cur *Node[any]) *Node[any] {
+	if cur != nil && cur.T == JSONMember {
+		return cur.P
+	}
+	return cur
+}
+
+// ToJSON is the inverse of FromJSON writing the Node (and every Node
+// under it) as JSON to the given writer. Every Node must have one of
+// the JSON* types (with V as described) or an error is returned. Any
+// JSONNumber value that is not a json.Number is marshaled as is.
+// Object keys are written in the order of the JSONMember nodes.
+func ToJSON(w io.Writer, n *Node[any]) error {
+	out := bufio.NewWriter(w)
+	var err error
+	enter := func(c *Node[any], _ int) Flow {
+		if c != n && c.left != nil && c.P.T != JSONMember {
+			out.WriteByte(',')
+		}
+		switch c.T {
+		case JSONObject:
+			out.WriteByte('{')
+		case JSONArray:
+			out.WriteByte('[')
+		case JSONMember:
+			if c.Count != 1 {
+				err = fmt.Errorf("member %q must have one value", c.V)
+				return Stop
+			}
+			err = writeJSON(out, c.V)
+			out.WriteByte(':')
+		case JSONNull:
+			out.WriteString("null")
+		case JSONString, JSONNumber, JSONBool:
+			if num, is := c.V.(std.Number); is {
+				out.WriteString(string(num))
+				break
+			}
+			err = writeJSON(out, c.V)
+		default:
+			err = fmt.Errorf("unsupported JSON type: %v", c.T)
+		}
+		if err != nil {
+			return Stop
+		}
+		return Continue
+	}
+	leave := func(c *Node[any], _ int) Flow {
+		switch c.T {
+		case JSONObject:
+			out.WriteByte('}')
+		case JSONArray:
+			out.WriteByte(']')
+		}
+		return Continue
+	}
+	n.WalkDeepFlow(enter, leave)
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func writeJSON(out *bufio.Writer, v any) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(buf)
+	return err
+}
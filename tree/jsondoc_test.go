@@ -0,0 +1,85 @@
+package tree_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleFromJSON() {
+	in := `{"name":"app","zeta":1.50,"alpha":[true,null,"<x>",{}],"big":12345678901234567890}`
+	t, err := tree.FromJSON(strings.NewReader(in))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(t.Render(nil))
+
+	// queries work on any JSON now
+	list, _ := t.Query(`//Member[V="alpha"]/Array/*`)
+	fmt.Println(len(list))
+
+	// and back again (order preserved)
+	tree.ToJSON(os.Stdout, t.Root)
+	fmt.Println()
+
+	// Output:
+	// Object
+	// ├── Member "name"
+	// │   └── String "app"
+	// ├── Member "zeta"
+	// │   └── Number 1.50
+	// ├── Member "alpha"
+	// │   └── Array
+	// │       ├── Bool true
+	// │       ├── Null
+	// │       ├── String "<x>"
+	// │       └── Object
+	// └── Member "big"
+	//     └── Number 12345678901234567890
+	// 4
+	// {"name":"app","zeta":1.50,"alpha":[true,null,"<x>",{}],"big":12345678901234567890}
+}
+
+func ExampleFromJSON_scalars() {
+	for _, in := range []string{`"just a string"`, `42`, `[]`, `[[1,[2]],{"a":{"b":null}}]`} {
+		t, err := tree.FromJSON(strings.NewReader(in))
+		fmt.Print(t.Root.T, " ", err, " ")
+		tree.ToJSON(os.Stdout, t.Root)
+		fmt.Println()
+	}
+	// Output:
+	// 4 <nil> "just a string"
+	// 5 <nil> 42
+	// 2 <nil> []
+	// 2 <nil> [[1,[2]],{"a":{"b":null}}]
+}
+
+func ExampleFromJSON_errors() {
+	for _, in := range []string{``, `{"a":`, `[1,2] 3`, `{"a" 1}`} {
+		_, err := tree.FromJSON(strings.NewReader(in))
+		fmt.Println(err)
+	}
+	// Output:
+	// unexpected EOF
+	// unexpected EOF
+	// unexpected data after JSON value
+	// invalid character '1' after object key
+}
+
+func ExampleToJSON() {
+	t := tree.New[any](tree.JSONTypes...)
+	t.Root.Add(tree.JSONMember, "n").Add(tree.JSONNumber, 3)
+	t.Root.Add(tree.JSONMember, "s").Add(tree.JSONString, "a\"b")
+	tree.ToJSON(os.Stdout, t.Root)
+	fmt.Println()
+
+	// members need exactly one value
+	t.Root.Add(tree.JSONMember, "bad")
+	fmt.Println(tree.ToJSON(os.Stdout, t.Root))
+
+	// Output:
+	// {"n":3,"s":"a\"b"}
+	// member "bad" must have one value
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/rwxrob/structs/types"
+)
+
+// Map returns a new tree with a different instantiation (U) but the
+// same shape as the one passed with every value converted by the val
+// function (which is passed every Node in document order). If typ is
+// not nil every type is also passed through it (ex: to merge or renumber
+// types). The Types (Names and Map) are copied as is and may need to
+// be changed afterward to match any new types. Spans (S) are copied.
+// The first error returned by val stops the conversion and is
+// returned (with the tree so far). See MapNode.
+func Map[T, U any](t *E[T], val func(n *Node[T]) (U, error), typ func(int) int) (*E[U], error) {
+	u := new(E[U])
+	u.Types = types.Types{
+		Names: slices.Clone(t.Names),
+		Map:   maps.Clone(t.Map),
+	}
+	if t.Root == nil {
+		return u, nil
+	}
+	var err error
+	u.Root, err = MapNode(t.Root, u, val, typ)
+	return u, err
+}
+
+// MapNode is the same as Map but converts only the Node passed (and
+// those under it) returning the new Node attached to the given tree
+// (which may be nil).
+func MapNode[T, U any](n *Node[T], tree *E[U], val func(n *Node[T]) (U, error), typ func(int) int) (*Node[U], error) {
+	type pair struct {
+		from *Node[T]
+		to   *Node[U]
+	}
+	var root *Node[U]
+	stack := []pair{{n, nil}}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		v, err := val(cur.from)
+		if err != nil {
+			return root, err
+		}
+		c := new(Node[U])
+		c.Tree = tree
+		c.T = cur.from.T
+		if typ != nil {
+			c.T = typ(c.T)
+		}
+		c.V = v
+		c.S = cur.from.S.copy()
+		if cur.to == nil {
+			root = c
+		} else {
+			cur.to.Append(c)
+		}
+		for k := cur.from.last; k != nil; k = k.left {
+			stack = append(stack, pair{k, c})
+		}
+	}
+	return root, nil
+}
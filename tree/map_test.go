@@ -0,0 +1,63 @@
+package tree_test
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleMap() {
+	t := tree.New[[]byte]("Sum", "Num", "Bool")
+	t.Root.Add(2, []byte("1"))
+	t.Root.Add(2, []byte("42"))
+	t.Root.Add(3, []byte("true"))
+
+	parsed, err := tree.Map(t, func(n *tree.Node[[]byte]) (any, error) {
+		switch n.T {
+		case 2:
+			return strconv.Atoi(string(n.V))
+		case 3:
+			return strconv.ParseBool(string(n.V))
+		}
+		return nil, nil
+	}, nil)
+	fmt.Println(err)
+	parsed.Print()
+	fmt.Printf("%T %T\n", parsed.Root.First().V, parsed.Root.Last().V)
+
+	// bad input stops the conversion
+	t.Root.Add(2, []byte("x"))
+	_, err = tree.Map(t, func(n *tree.Node[[]byte]) (int, error) {
+		if n.T != 2 {
+			return 0, nil
+		}
+		return strconv.Atoi(string(n.V))
+	}, nil)
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+	// {"Names":["UNKNOWN","Sum","Num","Bool"],"Map":{"Bool":3,"Num":2,"Sum":1,"UNKNOWN":0},"Root":{"T":1,"N":[{"T":2,"V":1},{"T":2,"V":42},{"T":3,"V":true}]}}
+	// int bool
+	// strconv.Atoi: parsing "x": invalid syntax
+}
+
+func ExampleMapNode() {
+	t := tree.New[string]("Doc", "Para", "Word")
+	p := t.Root.Add(2, "")
+	p.Add(3, "hello")
+	p.Add(3, "there")
+
+	words := tree.New[int]("Count")
+	n, _ := tree.MapNode(p, words,
+		func(n *tree.Node[string]) (int, error) { return len(n.V), nil },
+		func(int) int { return 1 },
+	)
+	n.Print()
+	fmt.Println(n.Tree == words)
+
+	// Output:
+	// {"T":1,"N":[{"T":1,"V":5},{"T":1,"V":5}]}
+	// true
+}
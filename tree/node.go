@@ -4,11 +4,14 @@
 package tree
 
 import (
+	std "encoding/json"
 	"fmt"
+	"iter"
 	"log"
 
 	json "github.com/rwxrob/json/pkg"
 	"github.com/rwxrob/structs/qstack"
+	"github.com/rwxrob/structs/types"
 )
 
 // Node is an implementation of a "node" from traditional rooted node
@@ -17,14 +20,15 @@ import (
 // instead. While there is nothing preventing a Node from having both
 // a value and other nodes under it, such use is unsupported by the
 // MarshalJSON/UnmarshalJSON methods. All nodes have a specific integer
-// type (T). See Tree for how to map type integers to human-friendly
-// names.
+// type (T). See E for how to map type integers to human-friendly
+// names (also used by the long form, MarshalJSONLong).
 type Node[T any] struct {
 	T     int      `json:"T"`          // type
 	V     T        `json:",omitempty"` // value
 	P     *Node[T] `json:"-"`          // up/parent
 	Count int      `json:"-"`          // node count
 	Tree  *E[T]    `json:"-"`          // optional tree with type names
+	S     *Span    `json:",omitempty"` // optional source position
 
 	left  *Node[T]
 	right *Node[T]
@@ -38,6 +42,7 @@ func (n *Node[T]) Init() {
 	var zv T // required to get go's idea of zero value for instantiated type
 	n.T = 0
 	n.V = zv
+	n.S = nil
 	n.first = nil
 	n.last = nil
 	n.left = nil
@@ -62,6 +67,78 @@ func (n *Node[T]) Nodes() []*Node[T] {
 	return list
 }
 
+// ---------------------------- Navigation -----------------------------
+
+// First returns the first Node under this one (or nil).
+func (n *Node[T]) First() *Node[T] { return n.first }
+
+// Last returns the last Node under this one (or nil).
+func (n *Node[T]) Last() *Node[T] { return n.last }
+
+// Left returns the sibling immediately before this one (or nil).
+func (n *Node[T]) Left() *Node[T] { return n.left }
+
+// Right returns the sibling immediately after this one (or nil).
+func (n *Node[T]) Right() *Node[T] { return n.right }
+
+// Prev is an alias for Left.
+func (n *Node[T]) Prev() *Node[T] { return n.left }
+
+// Next is an alias for Right.
+func (n *Node[T]) Next() *Node[T] { return n.right }
+
+// Root returns the top Node by following P until there are no more
+// (which might be this same Node).
+func (n *Node[T]) Root() *Node[T] {
+	cur := n
+	for cur.P != nil {
+		cur = cur.P
+	}
+	return cur
+}
+
+// Depth returns the number of nodes above this one (0 for the Root).
+func (n *Node[T]) Depth() int {
+	var d int
+	for cur := n.P; cur != nil; cur = cur.P {
+		d++
+	}
+	return d
+}
+
+// Index returns the position of this Node among its siblings starting
+// from 0 (which is always the case when there is no P).
+func (n *Node[T]) Index() int {
+	var i int
+	for cur := n.left; cur != nil; cur = cur.left {
+		i++
+	}
+	return i
+}
+
+// IsLeaf returns true if there are no nodes under this one.
+func (n *Node[T]) IsLeaf() bool { return n.first == nil }
+
+// IsRoot returns true if there is no node above this one.
+func (n *Node[T]) IsRoot() bool { return n.P == nil }
+
+// within returns true if this Node is the one passed or is under it
+// (in which case putting that one under this one would be a cycle).
+func (n *Node[T]) within(u *Node[T]) bool {
+	if u == n {
+		return true
+	}
+	if u.first == nil {
+		return false // nothing can be under it (fast for new nodes)
+	}
+	for cur := n.P; cur != nil; cur = cur.P {
+		if cur == u {
+			return true
+		}
+	}
+	return false
+}
+
 // --------------------------------------------------------------------
 
 // Add creates a new Node with type and value under and returns. It also
@@ -70,7 +147,6 @@ func (n *Node[T]) Add(t int, v T) *Node[T] {
 	u := new(Node[T])
 	u.T = t
 	u.V = v
-	u.P = n
 	u.Tree = n.Tree
 	n.Append(u)
 	return u
@@ -99,11 +175,15 @@ func (n *Node[T]) Cut() *Node[T] {
 	return n
 }
 
-// Take moves all nodes from another under itself.
+// Take moves all nodes from another under itself. Nothing is done if
+// the other is this Node or above it (which would be a cycle).
 func (n *Node[T]) Take(from *Node[T]) {
-	if from.first == nil {
+	if from.first == nil || n.within(from) {
 		return
 	}
+	for c := from.first; c != nil; c = c.right {
+		c.P = n
+	}
 	if n.first == nil {
 		n.first = from.first
 		n.last = from.last
@@ -119,9 +199,18 @@ func (n *Node[T]) Take(from *Node[T]) {
 	from.last = nil
 }
 
-// Append adds an existing Node under this one as if Add had been
-// called.
+// Append adds an existing Node under this one (after all others) as if
+// Add had been called. If the Node is already under another it is Cut
+// from there first. Nothing is done if the Node is this one or above it
+// (which would be a cycle).
 func (n *Node[T]) Append(u *Node[T]) {
+	if n.within(u) {
+		return
+	}
+	if u.P != nil {
+		u.Cut()
+	}
+	u.P = n
 	n.Count++
 	if n.first == nil {
 		n.first = u
@@ -133,14 +222,142 @@ func (n *Node[T]) Append(u *Node[T]) {
 	n.last = u
 }
 
-// Morph initializes the node with Init and then sets it's value (V) and
-// type (T) and all of its attachment references to those of the Node
-// passed thereby preserving the Node reference of this method's
-// receiver.
+// Prepend adds an existing Node under this one before all others. If
+// the Node is already under another it is Cut from there first. Nothing
+// is done if the Node is this one or above it (which would be a cycle).
+func (n *Node[T]) Prepend(u *Node[T]) {
+	if n.within(u) {
+		return
+	}
+	if u.P != nil {
+		u.Cut()
+	}
+	u.P = n
+	n.Count++
+	if n.first == nil {
+		n.first = u
+		n.last = u
+		return
+	}
+	n.first.left = u
+	u.right = n.first
+	n.first = u
+}
+
+// InsertBefore adds an existing Node as a sibling immediately before
+// this one (cutting it from wherever it was first). Nothing is done if
+// this Node is not under another (since only P tracks siblings) or if
+// the Node passed is this one or above it (which would be a cycle).
+func (n *Node[T]) InsertBefore(u *Node[T]) {
+	if n.P == nil || n.within(u) {
+		return
+	}
+	if u.P != nil {
+		u.Cut()
+	}
+	u.P = n.P
+	u.P.Count++
+	u.left = n.left
+	u.right = n
+	if n.left == nil {
+		n.P.first = u
+	} else {
+		n.left.right = u
+	}
+	n.left = u
+}
+
+// InsertAfter adds an existing Node as a sibling immediately after
+// this one (cutting it from wherever it was first). Nothing is done if
+// this Node is not under another (since only P tracks siblings) or if
+// the Node passed is this one or above it (which would be a cycle).
+func (n *Node[T]) InsertAfter(u *Node[T]) {
+	if n.P == nil || n.within(u) {
+		return
+	}
+	if u.P != nil {
+		u.Cut()
+	}
+	u.P = n.P
+	u.P.Count++
+	u.left = n
+	u.right = n.right
+	if n.right == nil {
+		n.P.last = u
+	} else {
+		n.right.left = u
+	}
+	n.right = u
+}
+
+// ReplaceWith puts the Node passed in the place of this one (cutting
+// it from wherever it was first) and returns this one after cutting it.
+// Nothing is done if this Node is not under another or if the Node
+// passed is this one or above it (which would be a cycle).
+func (n *Node[T]) ReplaceWith(u *Node[T]) *Node[T] {
+	if n.P == nil || n.within(u) {
+		return n
+	}
+	n.InsertBefore(u)
+	return n.Cut()
+}
+
+// Wrap puts the Node passed in the place of this one and then moves
+// this one under it (after any others already there) effectively
+// inserting a new parent. The new parent is returned. Nothing is done
+// (and nil is returned) if the Node passed is this one or above it
+// (which would be a cycle).
+func (n *Node[T]) Wrap(u *Node[T]) *Node[T] {
+	if n.within(u) {
+		return nil
+	}
+	if n.P != nil {
+		n.ReplaceWith(u)
+	} else if u.P != nil {
+		u.Cut()
+	}
+	u.Append(n)
+	return u
+}
+
+// Unwrap is the inverse of Wrap moving all nodes under this one into
+// its place within the one above it and then cutting and returning it.
+// Nothing is done if this Node is not under another.
+func (n *Node[T]) Unwrap() *Node[T] {
+	if n.P == nil {
+		return n
+	}
+	for c := n.first; c != nil; {
+		next := c.right
+		n.InsertBefore(c)
+		c = next
+	}
+	return n.Cut()
+}
+
+// Swap exchanges the places of this Node and the one passed, which may
+// be under different nodes. If only one of them is under another the
+// other simply takes its place. Nothing is done if either is above
+// the other (which would be a cycle).
+func (n *Node[T]) Swap(u *Node[T]) {
+	if n.within(u) || u.within(n) {
+		return
+	}
+	mark := new(Node[T])
+	n.ReplaceWith(mark)
+	u.ReplaceWith(n)
+	mark.ReplaceWith(u)
+}
+
+// Morph initializes the node with Init and then sets it's value (V),
+// type (T), span (S), and all of its attachment references to those of
+// the Node passed thereby preserving the Node reference of this
+// method's receiver.
 func (n *Node[T]) Morph(c *Node[T]) {
 	n.Init()
 	n.T = c.T
 	n.V = c.V
+	n.S = c.S.copy()
 	n.P = c.P
 	n.left = c.left
 	n.right = c.right
@@ -158,10 +375,10 @@ first: %-12p last:   %p`, n, n.P, n.left, n.right, n.first, n.last)
 }
 
 // Copy returns a duplicate of the Node and all its relations. Values
-// are copied using simple assignment. Copy is useful for preserving
-// state in order to revert a Node or to allow independent processing
-// with concurrency on individual copies. Note that Node[<ref>] types
-// will not produce deep copies of values.
+// are copied using simple assignment (but every Span is copied). Copy
+// is useful for preserving state in order to revert a Node or to allow
+// independent processing with concurrency on individual copies. Note
+// that Node[<ref>] types will not produce deep copies of values.
 func (n *Node[T]) Copy() *Node[T] {
 	clones := map[*Node[T]]*Node[T]{}
 	list := qstack.New[*Node[T]]()
@@ -170,6 +387,7 @@ func (n *Node[T]) Copy() *Node[T] {
 		cur := list.Shift()
 		list.Unshift(cur.Nodes()...)
 		c := *cur
+		c.S = cur.S.copy()
 		clones[cur] = &c
 	}
 	for _, clone := range clones {
@@ -216,11 +434,189 @@ func (n *Node[T]) WalkDeepPre(do func(n *Node[T])) {
 	}
 }
 
+// WalkDeepPost will pass each Node in the tree to the given function
+// traversing in a synchronous, depth-first, postorder way (every Node
+// after all of those under it). See WalkDeep.
+func (n *Node[T]) WalkDeepPost(do func(n *Node[T])) {
+	n.WalkDeep(nil, func(c *Node[T], _ int) { do(c) })
+}
+
+// WalkDeep traverses the tree in a synchronous, depth-first way passing
+// each Node and its depth (relative to this one, which is 0) first to
+// enter and then to leave after all the nodes under it have been
+// visited (similar to a visitor pattern). Either function may be nil.
+// Since a qstack.QS is used instead of functional recursion there is no
+// limit to the depth of the tree. See WalkDeepFlow.
+func (n *Node[T]) WalkDeep(enter, leave func(n *Node[T], d int)) {
+	var en, lv func(n *Node[T], d int) Flow
+	if enter != nil {
+		en = func(c *Node[T], d int) Flow { enter(c, d); return Continue }
+	}
+	if leave != nil {
+		lv = func(c *Node[T], d int) Flow { leave(c, d); return Continue }
+	}
+	n.WalkDeepFlow(en, lv)
+}
+
+// ----------------------------- Walk Flow -----------------------------
+
+// Flow is returned by the functions passed to the Walk*Flow methods to
+// control what is visited next.
+type Flow int
+
+const (
+	Continue Flow = iota // keep walking normally
+	Skip                 // do not visit the nodes under this one
+	Stop                 // stop walking entirely
+)
+
+// WalkLevelsFlow is the same as WalkLevels but the function controls
+// the walk (see Flow). The Node for which Stop was returned is
+// returned (or nil if never stopped).
+func (n *Node[T]) WalkLevelsFlow(do func(n *Node[T]) Flow) *Node[T] {
+	list := qstack.New[*Node[T]]()
+	list.Unshift(n)
+	for list.Len > 0 {
+		cur := list.Shift()
+		switch do(cur) {
+		case Stop:
+			return cur
+		case Skip:
+			continue
+		}
+		for c := cur.first; c != nil; c = c.right {
+			list.Push(c)
+		}
+	}
+	return nil
+}
+
+// WalkDeepPreFlow is the same as WalkDeepPre but the function controls
+// the walk (see Flow). The Node for which Stop was returned is
+// returned (or nil if never stopped).
+func (n *Node[T]) WalkDeepPreFlow(do func(n *Node[T]) Flow) *Node[T] {
+	return n.WalkDeepFlow(func(c *Node[T], _ int) Flow { return do(c) }, nil)
+}
+
+// WalkDeepPostFlow is the same as WalkDeepPost but the function
+// controls the walk (see Flow). Since the nodes under each Node have
+// already been visited Skip is the same as Continue. The Node for
+// which Stop was returned is returned (or nil if never stopped).
+func (n *Node[T]) WalkDeepPostFlow(do func(n *Node[T]) Flow) *Node[T] {
+	return n.WalkDeepFlow(nil, func(c *Node[T], _ int) Flow { return do(c) })
+}
+
+// just for tracking WalkDeepFlow visits
+type visit[T any] struct {
+	n     *Node[T]
+	d     int
+	leave bool
+}
+
+// WalkDeepFlow is the same as WalkDeep but the functions control the
+// walk (see Flow). When enter returns Skip none of the nodes under that
+// Node are visited but leave is still called for it. Skip from leave
+// is the same as Continue. The Node for which Stop was returned is
+// returned (or nil if never stopped).
+func (n *Node[T]) WalkDeepFlow(enter, leave func(n *Node[T], d int) Flow) *Node[T] {
+	list := qstack.New[visit[T]]()
+	list.Push(visit[T]{n: n})
+	for list.Len > 0 {
+		cur := list.Pop()
+		if cur.leave {
+			if leave != nil && leave(cur.n, cur.d) == Stop {
+				return cur.n
+			}
+			continue
+		}
+		var flow Flow
+		if enter != nil {
+			flow = enter(cur.n, cur.d)
+		}
+		if flow == Stop {
+			return cur.n
+		}
+		list.Push(visit[T]{cur.n, cur.d, true})
+		if flow == Skip {
+			continue
+		}
+		for c := cur.n.last; c != nil; c = c.left {
+			list.Push(visit[T]{n: c, d: cur.d + 1})
+		}
+	}
+	return nil
+}
+
+// ----------------------------- Iterators -----------------------------
+
+// All returns an iterator over this Node and every Node under it in
+// depth-first, preorder (document) order for use with range.
+func (n *Node[T]) All() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		n.WalkDeepFlow(func(c *Node[T], _ int) Flow {
+			if !yield(c) {
+				return Stop
+			}
+			return Continue
+		}, nil)
+	}
+}
+
+// Levels returns an iterator over this Node and every Node under it in
+// breadth-first order (see WalkLevels) for use with range.
+func (n *Node[T]) Levels() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		n.WalkLevelsFlow(func(c *Node[T]) Flow {
+			if !yield(c) {
+				return Stop
+			}
+			return Continue
+		})
+	}
+}
+
+// Children returns an iterator over the nodes directly under this one.
+// Prefer Nodes when a slice is wanted.
+func (n *Node[T]) Children() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		for c := n.first; c != nil; c = c.right {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// Ancestors returns an iterator over every Node above this one starting
+// with P and ending with the Root.
+func (n *Node[T]) Ancestors() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		for c := n.P; c != nil; c = c.P {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// Leaves returns an iterator over every Node under this one (or this
+// one itself) that has no nodes under it in preorder (document) order.
+func (n *Node[T]) Leaves() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		for c := range n.All() {
+			if c.first == nil && !yield(c) {
+				return
+			}
+		}
+	}
+}
+
 // ------------------------------ Printer -----------------------------
 // just for marshaling
 type jsnode[T any] struct {
 	T int
 	V T          `json:",omitempty"`
+	S *Span      `json:",omitempty"`
 	N []*Node[T] `json:",omitempty"`
 }
 
@@ -231,10 +627,134 @@ func (s Node[T]) MarshalJSON() ([]byte, error) {
 	n := new(jsnode[T])
 	n.T = s.T
 	n.V = s.V
+	n.S = s.S
 	n.N = s.Nodes()
 	return json.Marshal(n)
 }
 
+// just for long form marshaling
+type jslnode[T any] struct {
+	T any
+	V T             `json:",omitempty"`
+	S *Span         `json:",omitempty"`
+	N []*jslnode[T] `json:",omitempty"`
+}
+
+// long returns the long form of the node (and those under it) with the
+// types as strings from names wherever a name exists for the type.
+func (s *Node[T]) long(names types.Names) *jslnode[T] {
+	n := new(jslnode[T])
+	n.T = s.T
+	if s.T >= 0 && s.T < len(names) {
+		n.T = names[s.T]
+	}
+	n.V = s.V
+	n.S = s.S
+	for c := s.first; c != nil; c = c.right {
+		n.N = append(n.N, c.long(names))
+	}
+	return n
+}
+
+// MarshalJSONLong is the same as MarshalJSON but uses the type names
+// from the Tree (if any) instead of integers.
+func (s Node[T]) MarshalJSONLong() ([]byte, error) {
+	var names types.Names
+	if s.Tree != nil {
+		names = s.Tree.Names
+	}
+	return json.Marshal(s.long(names))
+}
+
+// just for unmarshaling either form
+type jsinnode struct {
+	T std.RawMessage
+	V std.RawMessage
+	S *Span
+	N []std.RawMessage
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler and is the inverse
+// of MarshalJSON (and MarshalJSONLong) rebuilding all the internal
+// links between the nodes (as well as P and Count). Any nodes already
+// under the receiver are discarded. If the receiver is attached to
+// a Tree every new Node is attached to it as well and type names are
+// looked up from its Types.Map.
+func (s *Node[T]) UnmarshalJSON(buf []byte) error {
+	var m types.Map
+	if s.Tree != nil {
+		m = s.Tree.Map
+	}
+	return s.unmarshal(buf, m)
+}
+
+func (s *Node[T]) unmarshal(buf []byte, m types.Map) error {
+	n := new(jsinnode)
+	if err := json.Unmarshal(buf, n); err != nil {
+		return err
+	}
+	typ, err := typeFromJSON(n.T, m)
+	if err != nil {
+		return err
+	}
+	var zv T
+	s.T = typ
+	s.V = zv
+	s.S = n.S
+	if len(n.V) > 0 {
+		if err := json.Unmarshal(n.V, &s.V); err != nil {
+			return err
+		}
+	}
+	s.discard()
+	for _, raw := range n.N {
+		c := new(Node[T])
+		c.Tree = s.Tree
+		if err := c.unmarshal(raw, m); err != nil {
+			return err
+		}
+		s.Append(c)
+	}
+	return nil
+}
+
+// discard cuts every Node under this one (leaving each one detached
+// from the others as well).
+func (s *Node[T]) discard() {
+	for c := s.first; c != nil; {
+		next := c.right
+		c.P = nil
+		c.left = nil
+		c.right = nil
+		c = next
+	}
+	s.first = nil
+	s.last = nil
+	s.Count = 0
+}
+
+// typeFromJSON returns the integer type from either the integer or the
+// quoted name form looking up the name in m.
+func typeFromJSON(raw std.RawMessage, m types.Map) (int, error) {
+	if len(raw) == 0 {
+		return types.UNKNOWN, nil
+	}
+	if raw[0] != '"' {
+		var t int
+		err := json.Unmarshal(raw, &t)
+		return t, err
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return types.UNKNOWN, err
+	}
+	t, has := m[name]
+	if !has {
+		return types.UNKNOWN, fmt.Errorf("unknown type name: %q", name)
+	}
+	return t, nil
+}
+
 // String implements rwxrob/json.Stringer and fmt.Stringer.
 func (s Node[T]) String() string {
 	byt, err := s.MarshalJSON()
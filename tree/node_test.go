@@ -2,6 +2,7 @@ package tree_test
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/rwxrob/structs/tree"
 )
@@ -16,7 +17,7 @@ func ExampleNode_type_is_Integer() {
 	// {"T":1}
 }
 
-func ExampleNode_value_Depends_on_Instantiation() {
+func ExampleNode_value_depends_on_instantiation() {
 
 	z := new(tree.Node[any])
 	z.Print() // values that are equiv of zero value for type are omitted
@@ -269,3 +270,570 @@ func ExampleNode_Copy() {
 	// {"T":0,"N":[{"T":2,"V":"some"},{"T":3,"V":"new","N":[{"T":4,"V":"deep"}]}]}
 
 }
+
+func ExampleNode_UnmarshalJSON() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, "one")
+	n.Add(2, nil).Add(22, "two")
+	buf, _ := n.MarshalJSON()
+
+	m := new(tree.Node[any])
+	if err := m.UnmarshalJSON(buf); err != nil {
+		fmt.Println(err)
+	}
+	m.Print()
+	fmt.Println(m.Count)
+
+	// links are rebuilt
+	kids := m.Nodes()
+	fmt.Println(kids[0].P == m, kids[1].P == m, kids[1].Nodes()[0].P == kids[1])
+
+	// and still usable
+	kids[0].Cut()
+	m.Print()
+	fmt.Println(m.Count)
+
+	// Output:
+	// {"T":0,"N":[{"T":1,"N":[{"T":11,"V":"one"}]},{"T":2,"N":[{"T":22,"V":"two"}]}]}
+	// 2
+	// true true true
+	// {"T":0,"N":[{"T":2,"N":[{"T":22,"V":"two"}]}]}
+	// 1
+}
+
+func ExampleNode_UnmarshalJSON_discarded() {
+	n := new(tree.Node[any])
+	old := n.Add(1, "old")
+	n.Add(2, "older")
+	n.UnmarshalJSON([]byte(`{"T":0,"N":[{"T":3}]}`))
+	fmt.Println(old.P == nil, old.Right() == nil)
+
+	// old nodes can be used anywhere without changing the receiver
+	n.Append(old)
+	n.Print()
+	fmt.Println(n.Count)
+
+	// Output:
+	// true true
+	// {"T":0,"N":[{"T":3},{"T":1,"V":"old"}]}
+	// 2
+}
+
+func ExampleNode_UnmarshalJSON_round_trip() {
+	n := new(tree.Node[any])
+	n.Add(2, "some")
+	c := n.Copy()
+	c.Add(3, "new").Add(4, "deep")
+	buf, _ := c.MarshalJSON()
+
+	m := new(tree.Node[any])
+	m.UnmarshalJSON(buf)
+	fmt.Println(m.String() == c.String())
+	m.Print()
+
+	// Output:
+	// true
+	// {"T":0,"N":[{"T":2,"V":"some"},{"T":3,"V":"new","N":[{"T":4,"V":"deep"}]}]}
+}
+
+func ExampleNode_MarshalJSONLong() {
+	t := tree.New[any]("Grammar", "Rule")
+	r := t.Root.Add(2, "foo")
+	buf, _ := r.MarshalJSONLong()
+	fmt.Println(string(buf))
+
+	// detached from any tree
+	n := new(tree.Node[any])
+	n.Add(2, "foo")
+	buf, _ = n.MarshalJSONLong()
+	fmt.Println(string(buf))
+
+	// Output:
+	// {"T":"Rule","V":"foo"}
+	// {"T":0,"N":[{"T":2,"V":"foo"}]}
+}
+
+func ExampleNode_Append_moves() {
+	n := new(tree.Node[any])
+	n.Add(1, nil)
+	c := n.Add(2, nil)
+	m := new(tree.Node[any])
+	m.Append(c) // cut from n first
+	n.Print()
+	m.Print()
+	fmt.Println(n.Count, m.Count, c.P == m)
+	// Output:
+	// {"T":0,"N":[{"T":1}]}
+	// {"T":0,"N":[{"T":2}]}
+	// 1 1 true
+}
+
+func ExampleNode_Prepend() {
+	n := new(tree.Node[any])
+	n.Prepend(&tree.Node[any]{T: 2})
+	n.Prepend(&tree.Node[any]{T: 1})
+	n.Print()
+	fmt.Println(n.Count, n.Nodes()[0].P == n)
+	// Output:
+	// {"T":0,"N":[{"T":1},{"T":2}]}
+	// 2 true
+}
+
+func ExampleNode_InsertBefore() {
+	n := new(tree.Node[any])
+	one := n.Add(1, nil)
+	three := n.Add(3, nil)
+	three.InsertBefore(&tree.Node[any]{T: 2})
+	one.InsertBefore(&tree.Node[any]{T: 0})
+	n.Print()
+	fmt.Println(n.Count)
+
+	// detached nodes have no siblings
+	x := new(tree.Node[any])
+	x.InsertBefore(&tree.Node[any]{T: 9})
+	x.Print()
+
+	// Output:
+	// {"T":0,"N":[{"T":0},{"T":1},{"T":2},{"T":3}]}
+	// 4
+	// {"T":0}
+}
+
+func ExampleNode_InsertAfter() {
+	n := new(tree.Node[any])
+	one := n.Add(1, nil)
+	three := n.Add(3, nil)
+	one.InsertAfter(&tree.Node[any]{T: 2})
+	three.InsertAfter(&tree.Node[any]{T: 4})
+	n.Print()
+	fmt.Println(n.Count)
+
+	// moving one already in the tree
+	three.InsertAfter(one)
+	n.Print()
+	fmt.Println(n.Count)
+
+	// Output:
+	// {"T":0,"N":[{"T":1},{"T":2},{"T":3},{"T":4}]}
+	// 4
+	// {"T":0,"N":[{"T":2},{"T":3},{"T":1},{"T":4}]}
+	// 4
+}
+
+func ExampleNode_ReplaceWith() {
+	n := new(tree.Node[any])
+	n.Add(1, nil)
+	two := n.Add(2, "old")
+	n.Add(3, nil)
+	x := two.ReplaceWith(&tree.Node[any]{T: 2, V: "new"})
+	n.Print()
+	x.Print()
+	fmt.Println(n.Count, x.P == nil)
+	// Output:
+	// {"T":0,"N":[{"T":1},{"T":2,"V":"new"},{"T":3}]}
+	// {"T":2,"V":"old"}
+	// 3 true
+}
+
+func ExampleNode_Wrap() {
+	n := new(tree.Node[any])
+	n.Add(1, nil)
+	two := n.Add(2, nil)
+	n.Add(3, nil)
+	w := two.Wrap(&tree.Node[any]{T: 9})
+	n.Print()
+	fmt.Println(n.Count, w.Count, two.P == w, w.P == n)
+	// Output:
+	// {"T":0,"N":[{"T":1},{"T":9,"N":[{"T":2}]},{"T":3}]}
+	// 3 1 true true
+}
+
+func ExampleNode_Append_cycle() {
+	n := new(tree.Node[any])
+	a := n.Add(1, nil)
+	b := a.Add(2, nil)
+	c := b.Add(3, nil)
+
+	// none of these would leave a tree
+	c.Append(a)
+	b.Prepend(b)
+	c.InsertBefore(b)
+	b.InsertAfter(a)
+	c.ReplaceWith(a)
+	c.Take(n)
+	fmt.Println(b.Wrap(a))
+	a.Swap(c)
+	c.Swap(a)
+	n.Print()
+	fmt.Println(a.P == n, b.P == a, c.P == b, n.Count, a.Count, b.Count)
+
+	// Output:
+	// <nil>
+	// {"T":0,"N":[{"T":1,"N":[{"T":2,"N":[{"T":3}]}]}]}
+	// true true true 1 1 1
+}
+
+func ExampleNode_Unwrap() {
+	n := new(tree.Node[any])
+	n.Add(1, nil)
+	w := n.Add(9, nil)
+	w.Add(2, nil)
+	w.Add(3, nil)
+	n.Add(4, nil)
+	n.Print()
+	x := w.Unwrap()
+	n.Print()
+	x.Print()
+	fmt.Println(n.Count, x.Count, n.Nodes()[1].P == n)
+	// Output:
+	// {"T":0,"N":[{"T":1},{"T":9,"N":[{"T":2},{"T":3}]},{"T":4}]}
+	// {"T":0,"N":[{"T":1},{"T":2},{"T":3},{"T":4}]}
+	// {"T":9}
+	// 4 0 true
+}
+
+func ExampleNode_Swap() {
+	n := new(tree.Node[any])
+	a := n.Add(1, nil)
+	b := n.Add(2, nil)
+	m := n.Add(3, nil)
+	c := m.Add(4, nil)
+
+	// neighbors
+	a.Swap(b)
+	n.Print()
+
+	// different parents
+	a.Swap(c)
+	n.Print()
+	fmt.Println(n.Count, m.Count, a.P == m, c.P == n)
+
+	// Output:
+	// {"T":0,"N":[{"T":2},{"T":1},{"T":3,"N":[{"T":4}]}]}
+	// {"T":0,"N":[{"T":2},{"T":4},{"T":3,"N":[{"T":1}]}]}
+	// 3 1 true true
+}
+
+func ExampleNode_Take_parents() {
+	n := new(tree.Node[any])
+	n.Add(1, nil)
+	m := new(tree.Node[any])
+	m.Take(n)
+	fmt.Println(m.Nodes()[0].P == m)
+	// Output:
+	// true
+}
+
+func ExampleNode_navigation() {
+	n := new(tree.Node[any])
+	one := n.Add(1, nil)
+	two := n.Add(2, nil)
+	three := n.Add(3, nil)
+	deep := two.Add(21, nil).Add(211, nil)
+
+	fmt.Println(n.First().T, n.Last().T)
+	fmt.Println(two.Left().T, two.Right().T, two.Prev().T, two.Next().T)
+	fmt.Println(one.Left() == nil, three.Right() == nil, one.First() == nil)
+	fmt.Println(deep.Root() == n, n.Root() == n)
+	fmt.Println(n.Depth(), two.Depth(), deep.Depth())
+	fmt.Println(one.Index(), two.Index(), three.Index(), n.Index())
+	fmt.Println(n.IsRoot(), one.IsRoot(), one.IsLeaf(), two.IsLeaf())
+
+	// walk the children without allocating
+	for c := n.First(); c != nil; c = c.Next() {
+		fmt.Print(c.T, " ")
+	}
+	fmt.Println()
+
+	// Output:
+	// 1 3
+	// 1 3 1 3
+	// true true true
+	// true true
+	// 0 1 3
+	// 0 1 2 0
+	// true false true false
+	// 1 2 3
+}
+
+func ExampleNode_WalkDeepPost() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+	n.WalkDeepPost(func(c *tree.Node[any]) { fmt.Print(c.T, " ") })
+	// Output:
+	// 11 1 22 2 33 3 0
+}
+
+func ExampleNode_WalkDeep() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil)
+	enter := func(c *tree.Node[any], d int) {
+		fmt.Printf("%v<%v>\n", strings.Repeat(" ", d), c.T)
+	}
+	leave := func(c *tree.Node[any], d int) {
+		fmt.Printf("%v</%v>\n", strings.Repeat(" ", d), c.T)
+	}
+	n.WalkDeep(enter, leave)
+	// Output:
+	// <0>
+	//  <1>
+	//   <11>
+	//   </11>
+	//  </1>
+	//  <2>
+	//  </2>
+	// </0>
+}
+
+func ExampleNode_WalkDeep_no_depth_limit() {
+	n := new(tree.Node[any])
+	cur := n
+	for i := 0; i < 100000; i++ {
+		cur = cur.Add(i, nil)
+	}
+	var max, left int
+	n.WalkDeep(
+		func(_ *tree.Node[any], d int) {
+			if d > max {
+				max = d
+			}
+		},
+		func(_ *tree.Node[any], _ int) { left++ },
+	)
+	fmt.Println(max, left)
+	// Output:
+	// 100000 100001
+}
+
+func ExampleNode_WalkLevelsFlow() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+
+	// skip
+	n.WalkLevelsFlow(func(c *tree.Node[any]) tree.Flow {
+		fmt.Print(c.T, ";")
+		if c.T == 2 {
+			return tree.Skip
+		}
+		return tree.Continue
+	})
+	fmt.Println()
+
+	// stop
+	found := n.WalkLevelsFlow(func(c *tree.Node[any]) tree.Flow {
+		fmt.Print(c.T, ";")
+		if c.T == 22 {
+			return tree.Stop
+		}
+		return tree.Continue
+	})
+	fmt.Println()
+	fmt.Println(found.T)
+
+	// never stopped
+	fmt.Println(n.WalkLevelsFlow(func(*tree.Node[any]) tree.Flow { return tree.Continue }))
+
+	// Output:
+	// 0;1;2;3;11;33;
+	// 0;1;2;3;11;22;
+	// 22
+	// <nil>
+}
+
+func ExampleNode_WalkDeepPreFlow() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+
+	// skip
+	n.WalkDeepPreFlow(func(c *tree.Node[any]) tree.Flow {
+		fmt.Print(c.T, ";")
+		if c.T == 2 {
+			return tree.Skip
+		}
+		return tree.Continue
+	})
+	fmt.Println()
+
+	// stop
+	found := n.WalkDeepPreFlow(func(c *tree.Node[any]) tree.Flow {
+		fmt.Print(c.T, ";")
+		if c.T == 22 {
+			return tree.Stop
+		}
+		return tree.Continue
+	})
+	fmt.Println()
+	fmt.Println(found.T)
+
+	// Output:
+	// 0;1;11;2;3;33;
+	// 0;1;11;2;22;
+	// 22
+}
+
+func ExampleNode_WalkDeepPostFlow() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+
+	// skip is meaningless after the fact
+	n.WalkDeepPostFlow(func(c *tree.Node[any]) tree.Flow {
+		fmt.Print(c.T, ";")
+		return tree.Skip
+	})
+	fmt.Println()
+
+	// stop
+	found := n.WalkDeepPostFlow(func(c *tree.Node[any]) tree.Flow {
+		fmt.Print(c.T, ";")
+		if c.T == 2 {
+			return tree.Stop
+		}
+		return tree.Continue
+	})
+	fmt.Println()
+	fmt.Println(found.T)
+
+	// Output:
+	// 11;1;22;2;33;3;0;
+	// 11;1;22;2;
+	// 2
+}
+
+func ExampleNode_WalkDeepFlow() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+
+	enter := func(c *tree.Node[any], d int) tree.Flow {
+		fmt.Printf("<%v>", c.T)
+		if c.T == 1 {
+			return tree.Skip
+		}
+		return tree.Continue
+	}
+	leave := func(c *tree.Node[any], d int) tree.Flow {
+		fmt.Printf("</%v>", c.T)
+		if c.T == 2 {
+			return tree.Stop
+		}
+		return tree.Continue
+	}
+	found := n.WalkDeepFlow(enter, leave)
+	fmt.Println()
+	fmt.Println(found.T)
+
+	// Output:
+	// <0><1></1><2><22></22></2>
+	// 2
+}
+
+func ExampleNode_All() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+
+	for c := range n.All() {
+		fmt.Print(c.T, ";")
+	}
+	fmt.Println()
+
+	// breaking out early
+	for c := range n.All() {
+		if c.T == 22 {
+			break
+		}
+		fmt.Print(c.T, ";")
+	}
+	fmt.Println()
+
+	// and nesting
+	for c := range n.Children() {
+		for d := range c.All() {
+			fmt.Print(d.T, ";")
+		}
+		fmt.Print(" ")
+	}
+	fmt.Println()
+
+	// Output:
+	// 0;1;11;2;22;3;33;
+	// 0;1;11;2;
+	// 1;11; 2;22; 3;33;
+}
+
+func ExampleNode_Levels() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil).Add(22, nil)
+	n.Add(3, nil).Add(33, nil)
+	for c := range n.Levels() {
+		if c.T == 22 {
+			break
+		}
+		fmt.Print(c.T, ";")
+	}
+	// Output:
+	// 0;1;2;3;11;
+}
+
+func ExampleNode_Children() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil)
+	n.Add(3, nil)
+	for c := range n.Children() {
+		fmt.Print(c.T, ";")
+		if c.T == 2 {
+			break
+		}
+	}
+	// Output:
+	// 1;2;
+}
+
+func ExampleNode_Ancestors() {
+	n := new(tree.Node[any])
+	d := n.Add(1, nil).Add(11, nil).Add(111, nil)
+	for c := range d.Ancestors() {
+		fmt.Print(c.T, ";")
+	}
+	fmt.Println()
+	for c := range d.Ancestors() {
+		fmt.Print(c.T, ";")
+		break
+	}
+	// Output:
+	// 11;1;0;
+	// 11;
+}
+
+func ExampleNode_Leaves() {
+	n := new(tree.Node[any])
+	n.Add(1, nil).Add(11, nil)
+	n.Add(2, nil)
+	n.Add(3, nil).Add(33, nil)
+	for c := range n.Leaves() {
+		fmt.Print(c.T, ";")
+	}
+	fmt.Println()
+	for c := range n.Leaves() {
+		if c.T == 33 {
+			break
+		}
+		fmt.Print(c.T, ";")
+	}
+	// Output:
+	// 11;2;33;
+	// 11;2;
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// just for tracking indentation while parsing outlines
+type outlined struct {
+	node   *Node[string]
+	indent string // of the nodes under it
+	set    bool   // if indent has been set
+}
+
+// ParseOutline reads indentation-structured text (nested lists, notes,
+// YAML-like data, etc.) and returns a new tree (initialized with the
+// given types) with a Node under the Root for every line that is not
+// blank. A line that is indented more than the line before it is under
+// that line. Lines with the same indentation are siblings. The value of
+// each Node is the line without its indentation or trailing white
+// space. The type is returned by the typeof function which is passed
+// that value and the depth of the Node (1 for those directly under the
+// Root). If typeof is nil every type is UNKNOWN (0). Indentation may be
+// any mix of spaces and tabs but every sibling must have exactly the
+// same indentation and every Node under another must have that same
+// indentation plus more. See WriteOutline.
+func ParseOutline(r io.Reader, typeof func(line string, depth int) int, types ...string) (*E[string], error) {
+	t := New[string](types...)
+	stack := []*outlined{{node: t.Root}}
+	in := bufio.NewReader(r)
+	for num := 1; ; num++ {
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return t, err
+		}
+		text := strings.TrimSpace(line)
+		if text != "" {
+			indent := line[:strings.Index(line, text)]
+			parent, err := outlineParent(&stack, indent)
+			if err != nil {
+				return t, fmt.Errorf("line %v: %v", num, err)
+			}
+			typ := 0
+			if typeof != nil {
+				typ = typeof(text, len(stack))
+			}
+			parent.Add(typ, text)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return t, nil
+}
+
+// outlineParent pops and pushes the stack as needed to find the Node
+// that a line with the given indentation belongs under.
+func outlineParent(stack *[]*outlined, indent string) (*Node[string], error) {
+	for {
+		top := (*stack)[len(*stack)-1]
+		switch {
+		case !top.set:
+			top.indent = indent
+			top.set = true
+			return top.node, nil
+		case indent == top.indent:
+			return top.node, nil
+		case len(indent) > len(top.indent) && strings.HasPrefix(indent, top.indent):
+			if top.node.last.first != nil {
+				return nil, fmt.Errorf("inconsistent indentation")
+			}
+			*stack = append(*stack, &outlined{node: top.node.last})
+		case len(*stack) == 1:
+			return nil, fmt.Errorf("inconsistent indentation")
+		default:
+			*stack = (*stack)[:len(*stack)-1]
+		}
+	}
+}
+
+// WriteOutline is the inverse of ParseOutline writing the value of
+// every Node under the one passed (but not itself) on its own line
+// indented once (with indent) for every Node above it up to the one
+// passed. Types are not written. Values should not contain line
+// returns.
+func WriteOutline(w io.Writer, n *Node[string], indent string) error {
+	out := bufio.NewWriter(w)
+	n.WalkDeep(func(c *Node[string], d int) {
+		if d == 0 {
+			return
+		}
+		out.WriteString(strings.Repeat(indent, d-1))
+		out.WriteString(c.V)
+		out.WriteString("\n")
+	}, nil)
+	return out.Flush()
+}
@@ -0,0 +1,93 @@
+package tree_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleParseOutline() {
+	in := `
+Shopping
+  - fruit
+    - apples
+    - pears
+
+  - bread
+Chores
+	- dishes
+`
+	typeof := func(line string, depth int) int {
+		if strings.HasPrefix(line, "- ") {
+			return 3
+		}
+		return 2
+	}
+	t, err := tree.ParseOutline(strings.NewReader(in), typeof, "Notes", "Heading", "Item")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(t.Render(nil))
+
+	// and back again
+	tree.WriteOutline(os.Stdout, t.Root, "  ")
+
+	// Output:
+	// Notes
+	// ├── Heading "Shopping"
+	// │   ├── Item "- fruit"
+	// │   │   ├── Item "- apples"
+	// │   │   └── Item "- pears"
+	// │   └── Item "- bread"
+	// └── Heading "Chores"
+	//     └── Item "- dishes"
+	// Shopping
+	//   - fruit
+	//     - apples
+	//     - pears
+	//   - bread
+	// Chores
+	//   - dishes
+}
+
+func ExampleWriteOutline() {
+	t := tree.New[string]("Doc")
+	a := t.Root.Add(0, "a")
+	a.Add(0, "b").Add(0, "c")
+	a.Add(0, "d")
+	t.Root.Add(0, "e")
+
+	buf := new(strings.Builder)
+	tree.WriteOutline(buf, t.Root, "\t")
+	fmt.Print(buf.String())
+
+	// round trip
+	u, err := tree.ParseOutline(strings.NewReader(buf.String()), nil, "Doc")
+	fmt.Println(err, u.String() == t.String())
+
+	// Output:
+	// a
+	// 	b
+	// 		c
+	// 	d
+	// e
+	// <nil> true
+}
+
+func ExampleParseOutline_errors() {
+	bad := []string{
+		"a\n    b\n  c\n",
+		"  a\nb\n",
+		"a\n  b\n\tc\n",
+	}
+	for _, in := range bad {
+		_, err := tree.ParseOutline(strings.NewReader(in), nil)
+		fmt.Println(err)
+	}
+	// Output:
+	// line 3: inconsistent indentation
+	// line 2: inconsistent indentation
+	// line 3: inconsistent indentation
+}
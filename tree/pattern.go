@@ -0,0 +1,555 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	std "encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	json "github.com/rwxrob/json/pkg"
+	"github.com/rwxrob/structs/types"
+)
+
+// Pattern is a compiled pattern (see ParsePattern) that matches
+// a Node (and those under it) by type, value, and shape. Patterns are
+// written as S-expressions (see MarshalSexp) with a few additions:
+//
+//	(Add (Num ?a) (Num ?b))   Add with exactly two Num nodes under it
+//	(Neg @x)                  Neg with any one Node under it
+//	(Call "print" @args...)   Call with "print" value and any nodes
+//	(_ "foo" ...)             any type with "foo" value and any nodes
+//	(Block (Return) @_...)    Block with a Return first
+//
+// The first item of every list is the type name (looked up with
+// types.Types.Map), an integer type, or an underscore (_) for any type.
+// It may be followed by a value which is either a JSON literal
+// (compared as a string with the value of the Node, and numbers also
+// with the literal exactly as written so that 1.50 matches
+// a json.Number of "1.50"), an underscore for any value,
+// or a question mark and name (?a) to capture the value. Any value
+// matches if none is given. Then come the patterns of the nodes under
+// it, which must match exactly and in order. An at sign and name (@x)
+// captures any one Node (and those under it) in that position and an
+// at sign with three dots after the name (@x...) captures all the
+// remaining nodes (if any) and must be last. Three dots alone (...)
+// matches any remaining nodes without capturing them. An underscore for
+// the name (@_ or @_...) does not capture. When the same capture name
+// is used more than once every Node (or value) must be Equal to the
+// first one captured.
+type Pattern struct {
+	src  string
+	root *pnode
+}
+
+const (
+	pvany = iota // omitted or _
+	pvlit        // JSON literal
+	pvcap        // ?name
+)
+
+type pnode struct {
+	any   bool   // _ type
+	name  string // type name
+	typ   int    // type (unless named)
+	named bool   // if name is to be looked up
+	val   int    // pvany, pvlit, pvcap
+	lit   string // JSON literal as written
+	str   string // literal as string for comparing
+	num   bool   // literal is a number
+	vcap  string // name after ?
+	kids  []*pnode
+	sub   bool   // @name (any Node) instead of list
+	rest  bool   // ... or @name...
+	ncap  string // name after @
+}
+
+// ParsePattern parses and compiles the pattern returning an error if it
+// is not valid. See Pattern for the syntax.
+func ParsePattern(p string) (*Pattern, error) {
+	root, err := parsePattern(p, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{src: p, root: root}, nil
+}
+
+// String implements fmt.Stringer returning the original pattern.
+func (p *Pattern) String() string { return p.src }
+
+// parsePattern parses both patterns and templates (see Rewrite) which
+// share the same syntax except that templates cannot have any type or
+// any value (_) or three dots alone (...).
+func parsePattern(src string, tmpl bool) (*pnode, error) {
+	s := newSexpScanner(strings.NewReader(src))
+	var stack []*pnode
+	var root *pnode
+	for root == nil {
+		tok, err := s.next()
+		if err != nil {
+			return nil, s.unexpected(err)
+		}
+		switch tok.kind {
+
+		case '(':
+			tok, err = s.next()
+			if err != nil {
+				return nil, s.unexpected(err)
+			}
+			if tok.kind != 'a' {
+				return nil, s.errorf("expected type")
+			}
+			p := new(pnode)
+			switch n, err := strconv.Atoi(tok.text); {
+			case tok.text == "_" && !tmpl:
+				p.any = true
+			case err == nil:
+				p.typ = n
+			case strings.ContainsAny(tok.text[:1], "?@.") || tok.text == "_":
+				return nil, s.errorf("invalid type: %v", tok.text)
+			default:
+				p.name = tok.text
+				p.named = true
+			}
+			next, err := s.peek()
+			if err != nil {
+				return nil, s.unexpected(err)
+			}
+			if next.kind == 's' || next.kind == 'a' && !strings.HasPrefix(next.text, "@") && next.text != "..." {
+				s.next()
+				switch {
+				case next.text == "_" && !tmpl:
+				case strings.HasPrefix(next.text, "?") && len(next.text) > 1:
+					p.val = pvcap
+					p.vcap = next.text[1:]
+				default:
+					var v any
+					if err := json.Unmarshal([]byte(next.text), &v); err != nil {
+						return nil, s.errorf("invalid value %v: %v", next.text, err)
+					}
+					p.val = pvlit
+					p.lit = next.text
+					p.str = valstr(v)
+					_, p.num = v.(float64)
+				}
+			}
+			if len(stack) > 0 {
+				if err := addPattern(stack[len(stack)-1], p); err != nil {
+					return nil, s.errorf("%v", err)
+				}
+			}
+			stack = append(stack, p)
+
+		case ')':
+			if len(stack) == 0 {
+				return nil, s.errorf("unexpected )")
+			}
+			if len(stack) == 1 {
+				root = stack[0]
+			}
+			stack = stack[:len(stack)-1]
+
+		case 'a':
+			if len(stack) == 0 {
+				return nil, s.errorf("unexpected %v", tok.text)
+			}
+			p := new(pnode)
+			switch {
+			case tok.text == "..." && !tmpl:
+				p.rest = true
+			case strings.HasPrefix(tok.text, "@") && len(tok.text) > 1:
+				name := tok.text[1:]
+				if strings.HasSuffix(name, "...") {
+					p.rest = true
+					name = strings.TrimSuffix(name, "...")
+				}
+				if name == "" || tmpl && name == "_" {
+					return nil, s.errorf("invalid capture: %v", tok.text)
+				}
+				p.sub = true
+				p.ncap = name
+			default:
+				return nil, s.errorf("unexpected %v", tok.text)
+			}
+			if err := addPattern(stack[len(stack)-1], p); err != nil {
+				return nil, s.errorf("%v", err)
+			}
+
+		default:
+			return nil, s.errorf("unexpected %v", tok.text)
+		}
+	}
+	if tok, err := s.next(); err == nil {
+		return nil, s.errorf("unexpected %v after pattern", tok.text)
+	}
+	return root, nil
+}
+
+func addPattern(parent, p *pnode) error {
+	if n := len(parent.kids); n > 0 && parent.kids[n-1].rest {
+		return fmt.Errorf("nothing may follow remaining nodes")
+	}
+	parent.kids = append(parent.kids, p)
+	return nil
+}
+
+// Captures contains everything captured by a Pattern that matched. Nodes
+// contains a single Node for every @name capture and all the remaining
+// ones (if any) for every @name... capture.
+type Captures[T any] struct {
+	Values map[string]T
+	Nodes  map[string][]*Node[T]
+}
+
+// Node returns the first Node captured with the name (or nil).
+func (c Captures[T]) Node(name string) *Node[T] {
+	if list := c.Nodes[name]; len(list) > 0 {
+		return list[0]
+	}
+	return nil
+}
+
+func (c Captures[T]) value(name string, v T) bool {
+	if name == "_" {
+		return true
+	}
+	if prev, has := c.Values[name]; has {
+		return deepEqual(prev, v)
+	}
+	c.Values[name] = v
+	return true
+}
+
+func (c Captures[T]) nodes(name string, list []*Node[T]) bool {
+	if name == "" || name == "_" {
+		return true
+	}
+	prev, has := c.Nodes[name]
+	if !has {
+		c.Nodes[name] = list
+		return true
+	}
+	if len(prev) != len(list) {
+		return false
+	}
+	for i, n := range prev {
+		if !n.Equal(list[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ----------------------------- matching -----------------------------
+
+// Match returns everything captured and true if the Pattern matches
+// this Node (and those under it). Type names are looked up from the
+// Tree and those that cannot be found never match.
+func (n *Node[T]) Match(p *Pattern) (Captures[T], bool) {
+	var m types.Map
+	if n.Tree != nil {
+		m = n.Tree.Map
+	}
+	c := Captures[T]{map[string]T{}, map[string][]*Node[T]{}}
+	type pair struct {
+		p *pnode
+		n *Node[T]
+	}
+	stack := []pair{{p.root, n}}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		p, n := cur.p, cur.n
+
+		if p.sub {
+			if !c.nodes(p.ncap, []*Node[T]{n}) {
+				return c, false
+			}
+			continue
+		}
+
+		if !p.any {
+			typ := p.typ
+			if p.named {
+				t, has := m[p.name]
+				if !has {
+					return c, false
+				}
+				typ = t
+			}
+			if n.T != typ {
+				return c, false
+			}
+		}
+
+		switch p.val {
+		case pvlit:
+			if v := valstr(n.V); v != p.str && !(p.num && v == p.lit) {
+				return c, false
+			}
+		case pvcap:
+			if !c.value(p.vcap, n.V) {
+				return c, false
+			}
+		}
+
+		k := n.first
+		for _, pk := range p.kids {
+			if pk.rest {
+				var rest []*Node[T]
+				for ; k != nil; k = k.right {
+					rest = append(rest, k)
+				}
+				if !c.nodes(pk.ncap, rest) {
+					return c, false
+				}
+				break
+			}
+			if k == nil {
+				return c, false
+			}
+			stack = append(stack, pair{pk, k})
+			k = k.right
+		}
+		if k != nil {
+			return c, false
+		}
+	}
+	return c, true
+}
+
+// ----------------------------- rewriting ----------------------------
+
+// Order is the order in which Rewrite visits the nodes of a tree.
+type Order int
+
+const (
+	TopDown  Order = iota // every Node before those under it
+	BottomUp              // every Node after those under it
+)
+
+// Rewrite is a rule that replaces every Node that matches a Pattern
+// (see ParsePattern) with either the Replace template or the Node
+// returned by Func. Replace is written the same as a Pattern but every
+// ?name is replaced with the captured value, every @name (or @name...)
+// with the captured nodes (which are moved and then copied if used
+// again), and every other value (written as JSON) is converted with
+// Literal (if not nil) or else unmarshaled into a new value (keeping
+// numbers as json.Number when T is an interface, ex: any). Func is only
+// called when Replace is empty. It returns the
+// Node to put in its place (which may be nil to remove it, or the Node
+// itself after changing it in place) and false if the rule should not
+// be applied after all. Returning the Node itself is not counted as
+// a change (and never causes another pass by itself). Nodes returned by
+// Func can be new or any of those under it (which are Cut as needed).
+type Rewrite[T any] struct {
+	Match   string
+	Replace string
+	Func    func(n *Node[T], c Captures[T]) (*Node[T], bool)
+	Literal func(lit string) (T, error)
+}
+
+type rewrite[T any] struct {
+	pat  *Pattern
+	tmpl *pnode
+	fn   func(n *Node[T], c Captures[T]) (*Node[T], bool)
+	lit  func(lit string) (T, error)
+}
+
+// DefaultRewritePasses is the number of passes over a tree that Rewrite
+// makes before giving up on ever reaching a fixed point. See
+// RewriteLimit.
+const DefaultRewritePasses = 1000
+
+// Rewrite applies the rules to every Node of the tree in the given
+// Order, trying each rule in turn until one is applied, and then keeps
+// doing so until none of the rules change any Node (a fixed point)
+// returning the total number of changes. Nodes added (or moved) by
+// a rule are not visited again until the next pass over the tree. An
+// error is returned if any rule is not valid, a rule would remove the
+// Root, or there are still changes after DefaultRewritePasses (such as
+// when rules undo each other or a template matches its own pattern).
+func (t *E[T]) Rewrite(o Order, rules ...Rewrite[T]) (int, error) {
+	return t.RewriteLimit(o, DefaultRewritePasses, rules...)
+}
+
+// RewriteLimit is the same as Rewrite but gives up after the given
+// number of passes (instead of DefaultRewritePasses).
+func (t *E[T]) RewriteLimit(o Order, passes int, rules ...Rewrite[T]) (int, error) {
+	compiled := make([]rewrite[T], len(rules))
+	for i, r := range rules {
+		pat, err := ParsePattern(r.Match)
+		if err != nil {
+			return 0, err
+		}
+		compiled[i] = rewrite[T]{pat: pat, fn: r.Func, lit: r.Literal}
+		switch {
+		case r.Replace != "":
+			tmpl, err := parsePattern(r.Replace, true)
+			if err != nil {
+				return 0, err
+			}
+			if err := resolveTemplate(tmpl, t.Map); err != nil {
+				return 0, err
+			}
+			compiled[i].tmpl = tmpl
+		case r.Func == nil:
+			return 0, fmt.Errorf("rule has neither Replace nor Func: %v", r.Match)
+		}
+	}
+
+	var count int
+	for pass := 0; t.Root != nil; pass++ {
+		if pass == passes {
+			return count, fmt.Errorf("rewrite still changing after %v passes", pass)
+		}
+		var list []*Node[T]
+		if o == BottomUp {
+			t.Root.WalkDeepPost(func(n *Node[T]) { list = append(list, n) })
+		} else {
+			for n := range t.Root.All() {
+				list = append(list, n)
+			}
+		}
+		var changed int
+		for _, n := range list {
+			if n.Root() != t.Root {
+				continue // no longer in the tree
+			}
+			for _, r := range compiled {
+				c, ok := n.Match(r.pat)
+				if !ok {
+					continue
+				}
+				var with *Node[T]
+				if r.tmpl != nil {
+					var err error
+					if with, err = buildTemplate(t, r.tmpl, r.lit, c); err != nil {
+						return count, err
+					}
+				} else if with, ok = r.fn(n, c); !ok {
+					continue
+				}
+				if with == n {
+					break // changed in place (if at all)
+				}
+				if err := t.replace(n, with); err != nil {
+					return count, err
+				}
+				changed++
+				break
+			}
+		}
+		if changed == 0 {
+			break
+		}
+		count += changed
+	}
+	return count, nil
+}
+
+// replace puts with in the place of n (removing n if nil).
+func (t *E[T]) replace(n, with *Node[T]) error {
+	switch {
+	case with == n:
+	case n == t.Root:
+		if with == nil {
+			return fmt.Errorf("cannot remove Root")
+		}
+		if with.P != nil {
+			with.Cut()
+		}
+		t.Root = with
+	case with == nil:
+		n.Cut()
+	default:
+		n.ReplaceWith(with)
+	}
+	return nil
+}
+
+// resolveTemplate looks up every type name of a template.
+func resolveTemplate(p *pnode, m types.Map) error {
+	stack := []*pnode{p}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if p.named {
+			t, has := m[p.name]
+			if !has {
+				return fmt.Errorf("unknown type name: %q", p.name)
+			}
+			p.typ = t
+			p.named = false
+		}
+		stack = append(stack, p.kids...)
+	}
+	return nil
+}
+
+// buildTemplate returns a new Node (and those under it) from the
+// template using the captures and lit (if not nil) for literal values.
+func buildTemplate[T any](t *E[T], p *pnode, lit func(string) (T, error), c Captures[T]) (*Node[T], error) {
+	used := map[*Node[T]]bool{}
+	type pair struct {
+		p      *pnode
+		parent *Node[T]
+	}
+	var root *Node[T]
+	stack := []pair{{p, nil}}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		p := cur.p
+
+		if p.sub {
+			list, has := c.Nodes[p.ncap]
+			if !has {
+				return nil, fmt.Errorf("nothing captured: @%v", p.ncap)
+			}
+			for _, n := range list {
+				if used[n] {
+					n = n.Copy()
+				}
+				used[n] = true
+				cur.parent.Append(n)
+			}
+			continue
+		}
+
+		var v T
+		switch p.val {
+		case pvlit:
+			if lit != nil {
+				var err error
+				if v, err = lit(p.lit); err != nil {
+					return nil, fmt.Errorf("template value %v: %v", p.lit, err)
+				}
+				break
+			}
+			dec := std.NewDecoder(strings.NewReader(p.lit))
+			dec.UseNumber()
+			if err := dec.Decode(&v); err != nil {
+				return nil, err
+			}
+		case pvcap:
+			val, has := c.Values[p.vcap]
+			if !has {
+				return nil, fmt.Errorf("nothing captured: ?%v", p.vcap)
+			}
+			v = val
+		}
+		n := t.Node(p.typ, v)
+		if cur.parent == nil {
+			root = n
+		} else {
+			cur.parent.Append(n)
+		}
+		for i := len(p.kids) - 1; i >= 0; i-- {
+			stack = append(stack, pair{p.kids[i], n})
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,175 @@
+package tree_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+// 1 + 2 * -x
+func arith() *tree.E[any] {
+	t := tree.New[any]("Expr", "Add", "Mul", "Neg", "Sub", "Num", "Var")
+	add := t.Root.Add(2, nil)
+	add.Add(6, 1)
+	mul := add.Add(3, nil)
+	mul.Add(6, 2)
+	mul.Add(4, nil).Add(7, "x")
+	return t
+}
+
+func ExampleNode_Match() {
+	t := arith()
+	p, _ := tree.ParsePattern(`(Add (Num ?a) @rest...)`)
+	c, ok := t.Root.First().Match(p)
+	fmt.Print(ok, " ", c.Values["a"], " ", c.Node("rest").Render(&tree.RenderOpts{ASCII: true}))
+
+	p, _ = tree.ParsePattern(`(Mul (Num 2) (Neg (Var ?v)))`)
+	c, ok = t.Root.First().Last().Match(p)
+	fmt.Println(ok, c.Values["v"])
+
+	p, _ = tree.ParsePattern(`(_ (Num ?a) (Num ?a))`)
+	_, ok = t.Root.First().Match(p)
+	fmt.Println(ok)
+
+	// Output:
+	// true 1 Mul
+	// |-- Num 2
+	// `-- Neg
+	//     `-- Var "x"
+	// true x
+	// false
+}
+
+func ExampleParsePattern_errors() {
+	for _, p := range []string{
+		`Add`,
+		`(Add ... @x)`,
+		`(Add (Num)`,
+		`(?x)`,
+		`(Add) (Sub)`,
+	} {
+		_, err := tree.ParsePattern(p)
+		fmt.Println(err)
+	}
+	// Output:
+	// line 1: unexpected Add
+	// line 1: nothing may follow remaining nodes
+	// unexpected EOF
+	// line 1: invalid type: ?x
+	// line 1: unexpected ( after pattern
+}
+
+func ExampleE_Rewrite() {
+	t := arith()
+	n, err := t.Rewrite(tree.BottomUp,
+		tree.Rewrite[any]{
+			Match:   `(Neg @x)`,
+			Replace: `(Sub (Num 0) @x)`,
+			Literal: func(lit string) (any, error) { return strconv.Atoi(lit) },
+		},
+		tree.Rewrite[any]{
+			Match: `(Mul (Num ?a) (Num ?b))`,
+			Func: func(n *tree.Node[any], c tree.Captures[any]) (*tree.Node[any], bool) {
+				a, aok := c.Values["a"].(int)
+				b, bok := c.Values["b"].(int)
+				if !aok || !bok {
+					return n, false
+				}
+				return n.Tree.Node(6, a*b), true
+			},
+		},
+	)
+	fmt.Println(n, err)
+	s, _ := t.MarshalSexp() // zero values (0) are not written
+	fmt.Println(string(s))
+	nums, _ := t.Query(`//Num`)
+	fmt.Printf("%T\n", nums[2].V) // from Literal
+
+	// fold what can be folded once x is known
+	x, _ := t.Query(`//Var`)
+	x[0].ReplaceWith(t.Node(6, 3))
+	n, err = t.Rewrite(tree.TopDown,
+		tree.Rewrite[any]{
+			Match: `(_ (Num ?a) (Num ?b))`,
+			Func: func(n *tree.Node[any], c tree.Captures[any]) (*tree.Node[any], bool) {
+				a, aok := c.Values["a"].(int)
+				b, bok := c.Values["b"].(int)
+				if !aok || !bok {
+					return n, false
+				}
+				switch n.Tree.Names[n.T] {
+				case "Add":
+					return n.Tree.Node(6, a+b), true
+				case "Sub":
+					return n.Tree.Node(6, a-b), true
+				case "Mul":
+					return n.Tree.Node(6, a*b), true
+				}
+				return n, false
+			},
+		},
+	)
+	fmt.Println(n, err)
+	s, _ = t.MarshalSexp()
+	fmt.Println(string(s))
+
+	// Output:
+	// 1 <nil>
+	// (Expr (Add (Num 1) (Mul (Num 2) (Sub (Num) (Var "x")))))
+	// int
+	// 3 <nil>
+	// (Expr (Num -5))
+}
+
+func ExampleNode_Match_numbers() {
+	t, _ := tree.FromJSON(strings.NewReader(`{"price":1.50}`))
+	p, _ := tree.ParsePattern(`(Member "price" (Number 1.50))`)
+	_, ok := t.Root.First().Match(p)
+	fmt.Println(ok)
+
+	n, err := t.Rewrite(tree.BottomUp, tree.Rewrite[any]{
+		Match:   `(Member "price" (Number 1.50))`,
+		Replace: `(Member "price" (Number 2.00))`,
+	})
+	fmt.Println(n, err)
+	v := t.Root.First().First().V
+	fmt.Printf("%T %v\n", v, v)
+
+	// literals keep their own value even among other types
+	a := arith()
+	n, err = a.Rewrite(tree.BottomUp, tree.Rewrite[any]{
+		Match:   `(Num 1)`,
+		Replace: `(Num 2.5)`,
+	})
+	v = a.Root.First().First().V
+	fmt.Printf("%v %v %T %v\n", n, err, v, v)
+
+	// Output:
+	// true
+	// 1 <nil>
+	// json.Number 2.00
+	// 1 <nil> json.Number 2.5
+}
+
+func ExampleE_Rewrite_limit() {
+	t := arith()
+	n, err := t.RewriteLimit(tree.BottomUp, 10, tree.Rewrite[any]{
+		Match:   `(Num ?a)`,
+		Replace: `(Num ?a)`, // always matches what it leaves
+	})
+	fmt.Println(n, err)
+
+	n, err = t.Rewrite(tree.BottomUp, tree.Rewrite[any]{
+		Match: `(Num ?a)`,
+		Func: func(n *tree.Node[any], c tree.Captures[any]) (*tree.Node[any], bool) {
+			return n, true // unchanged
+		},
+	})
+	fmt.Println(n, err)
+
+	// Output:
+	// 20 rewrite still changing after 10 passes
+	// 0 <nil>
+}
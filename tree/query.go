@@ -0,0 +1,555 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rwxrob/structs/types"
+)
+
+// Query is a compiled path query (see ParseQuery) that can be used to
+// Select nodes from any tree (or Node) without parsing it again. Query
+// paths are a small subset of XPath ideas adapted to nodes that only
+// have a type and a value:
+//
+//	/Grammar/Rule     Rule nodes directly under a Grammar Root
+//	//Function//Param Param nodes anywhere under Function nodes
+//	Rule              Rule nodes directly under the context Node
+//	//*[V="foo"]      any Node with a value of "foo"
+//	//Rule[2]         second Rule under each Node with Rule nodes
+//	//Rule[-1]        last Rule under each Node with Rule nodes
+//	//Param/..        nodes with Param nodes under them
+//	//*[depth<=2]     any Node no more than two below the Root
+//	//Ident[V~"^x"]   Ident nodes with values matching the regexp
+//	//Ident[V]        Ident nodes with any non-zero value
+//	//3               nodes with the integer type of 3
+//
+// A single slash (/) selects the nodes directly under those of the
+// previous step and a double slash (//) selects from every Node under
+// them (at any depth) instead. Each step is either a type name (looked
+// up with types.Types.Map), an integer type, an asterisk (*) for any
+// type, a dot (.) for the same Node or two dots (..) for the Node above
+// it. Type names with any other characters than letters, digits,
+// underscores, dashes, and dots (or that could be confused with an
+// integer or dots) must be in quotes (ex: //"my type"). A leading slash
+// starts from the Root itself (as if it had a parent) instead of the
+// context Node. Each step may be followed by any number of predicates
+// in brackets which filter the matches in order: integers (1 is first,
+// -1 is last) select by position among the matches for each Node of the
+// previous step, depth (from the Root which is 0) and V (value as a
+// string) may be compared with =, !=, <, <=, >, >=, or ~ (regular
+// expression). Numbers are compared as numbers when both sides are
+// numbers. Strings must be in double or single quotes.
+type Query struct {
+	src   string
+	abs   bool
+	steps []qstep
+}
+
+const (
+	qchild = iota // direct children
+	qdesc         // every node under (and self) then children
+)
+
+const (
+	qtest   = iota // type test (or *)
+	qself          // .
+	qparent        // ..
+)
+
+type qstep struct {
+	axis  int
+	kind  int
+	any   bool
+	name  string
+	typ   int
+	named bool
+	preds []qpred
+}
+
+const (
+	qpos   = iota // position [n]
+	qdepth        // depth op n
+	qval          // V op literal
+	qhas          // V
+)
+
+type qpred struct {
+	kind int
+	op   string
+	n    int
+	s    string
+	re   *regexp.Regexp
+}
+
+// ParseQuery parses and compiles the query returning an error if it is
+// not valid. See Query for the syntax.
+func ParseQuery(q string) (*Query, error) {
+	p := &qparser{buf: []rune(strings.TrimSpace(q))}
+	query := &Query{src: q}
+	if len(p.buf) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	axis := qchild
+	switch {
+	case p.eat("//"):
+		query.abs = true
+		axis = qdesc
+	case p.eat("/"):
+		query.abs = true
+	}
+	for {
+		s, err := p.step(axis)
+		if err != nil {
+			return nil, err
+		}
+		query.steps = append(query.steps, s)
+		if p.done() {
+			break
+		}
+		switch {
+		case p.eat("//"):
+			axis = qdesc
+		case p.eat("/"):
+			axis = qchild
+		default:
+			return nil, p.errorf("expected / or //")
+		}
+	}
+	return query, nil
+}
+
+// String implements fmt.Stringer returning the original query.
+func (q *Query) String() string { return q.src }
+
+// ----------------------------- parsing -----------------------------
+
+type qparser struct {
+	buf []rune
+	pos int
+}
+
+func (p *qparser) done() bool { return p.pos >= len(p.buf) }
+
+func (p *qparser) errorf(format string, a ...any) error {
+	return fmt.Errorf("query %q at %v: %v",
+		string(p.buf), p.pos, fmt.Sprintf(format, a...))
+}
+
+func (p *qparser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.buf[p.pos]
+}
+
+func (p *qparser) eat(s string) bool {
+	r := []rune(s)
+	if p.pos+len(r) > len(p.buf) || string(p.buf[p.pos:p.pos+len(r)]) != s {
+		return false
+	}
+	p.pos += len(r)
+	return true
+}
+
+func (p *qparser) space() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func isword(r rune) bool {
+	return r == '_' || r == '-' || r == '.' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9') || r > 127
+}
+
+func (p *qparser) word() string {
+	beg := p.pos
+	for !p.done() && isword(p.peek()) {
+		p.pos++
+	}
+	return string(p.buf[beg:p.pos])
+}
+
+// quoted returns the string in double or single quotes (with any
+// character escaped with a backslash) after the current position.
+func (p *qparser) quoted() (string, error) {
+	q := p.peek()
+	if q != '"' && q != '\'' {
+		return "", p.errorf("expected quoted string")
+	}
+	p.pos++
+	var lit []rune
+	for {
+		if p.done() {
+			return "", p.errorf("unterminated string")
+		}
+		r := p.buf[p.pos]
+		p.pos++
+		if r == q {
+			break
+		}
+		if r == '\\' && !p.done() {
+			r = p.buf[p.pos]
+			p.pos++
+		}
+		lit = append(lit, r)
+	}
+	return string(lit), nil
+}
+
+func (p *qparser) step(axis int) (qstep, error) {
+	s := qstep{axis: axis}
+	switch {
+	case p.eat("*"):
+		s.any = true
+	case p.peek() == '"' || p.peek() == '\'':
+		name, err := p.quoted()
+		if err != nil {
+			return s, err
+		}
+		s.name = name
+		s.named = true
+	default:
+		w := p.word()
+		switch w {
+		case "":
+			return s, p.errorf("expected type name, integer, *, . or ..")
+		case ".":
+			s.kind = qself
+		case "..":
+			s.kind = qparent
+		default:
+			if n, err := strconv.Atoi(w); err == nil {
+				s.typ = n
+			} else {
+				s.name = w
+				s.named = true
+			}
+		}
+	}
+	for p.eat("[") {
+		p.space()
+		pr, err := p.pred()
+		if err != nil {
+			return s, err
+		}
+		p.space()
+		if !p.eat("]") {
+			return s, p.errorf("expected ]")
+		}
+		s.preds = append(s.preds, pr)
+	}
+	return s, nil
+}
+
+func (p *qparser) pred() (qpred, error) {
+	var pr qpred
+	w := p.word()
+	switch w {
+	case "depth":
+		pr.kind = qdepth
+	case "V":
+		pr.kind = qval
+	default:
+		n, err := strconv.Atoi(w)
+		if err != nil || n == 0 {
+			return pr, p.errorf("expected position, depth or V")
+		}
+		pr.kind = qpos
+		pr.n = n
+		return pr, nil
+	}
+	p.space()
+	for _, op := range []string{"!=", "<=", ">=", "=", "<", ">", "~"} {
+		if p.eat(op) {
+			pr.op = op
+			break
+		}
+	}
+	if pr.op == "" {
+		if pr.kind == qval && p.peek() == ']' {
+			pr.kind = qhas
+			return pr, nil
+		}
+		return pr, p.errorf("expected comparison")
+	}
+	p.space()
+	if pr.kind == qdepth {
+		n, err := strconv.Atoi(p.word())
+		if err != nil {
+			return pr, p.errorf("expected integer depth")
+		}
+		if pr.op == "~" {
+			return pr, p.errorf("cannot use ~ with depth")
+		}
+		pr.n = n
+		return pr, nil
+	}
+	lit, err := p.quoted()
+	if err != nil {
+		return pr, err
+	}
+	pr.s = lit
+	if pr.op == "~" {
+		re, err := regexp.Compile(pr.s)
+		if err != nil {
+			return pr, p.errorf("%v", err)
+		}
+		pr.re = re
+	}
+	return pr, nil
+}
+
+// ---------------------------- selecting -----------------------------
+
+// Query parses the query (see Query) and selects from the Root. A query
+// without a leading slash starts from the Root (so the first step
+// matches nodes directly under it).
+func (t *E[T]) Query(q string) ([]*Node[T], error) {
+	query, err := ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	return t.Select(query)
+}
+
+// Select returns every Node matching the compiled Query starting from
+// the Root in document (preorder) order.
+func (t *E[T]) Select(q *Query) ([]*Node[T], error) {
+	if t.Root == nil {
+		return nil, nil
+	}
+	return selectNodes(t.Root, q, t.Map)
+}
+
+// Query parses the query (see Query) and selects from this Node
+// looking up any type names from its Tree.
+func (n *Node[T]) Query(q string) ([]*Node[T], error) {
+	query, err := ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	return n.Select(query)
+}
+
+// Select returns every Node matching the compiled Query starting from
+// this Node in document (preorder) order. Type names are looked up from
+// the Tree (which is required if any are used).
+func (n *Node[T]) Select(q *Query) ([]*Node[T], error) {
+	var m types.Map
+	if n.Tree != nil {
+		m = n.Tree.Map
+	}
+	return selectNodes(n, q, m)
+}
+
+// Path returns a query (see Query) that selects only this Node from
+// its Root (ex: /Grammar/Rule[2]/Ident[1]). Each position is among the
+// nodes of the same type under the same Node. Types are written by
+// name (quoted if needed) when the Tree has one for them.
+func (n *Node[T]) Path() string {
+	var steps []string
+	for c := n; c != nil; c = c.P {
+		step := strconv.Itoa(c.T)
+		if c.Tree != nil && c.T >= 0 && c.T < len(c.Tree.Names) {
+			step = qname(c.Tree.Names[c.T])
+		}
+		if c.P != nil {
+			pos := 1
+			for l := c.left; l != nil; l = l.left {
+				if l.T == c.T {
+					pos++
+				}
+			}
+			step += "[" + strconv.Itoa(pos) + "]"
+		}
+		steps = append(steps, step)
+	}
+	buf := new(strings.Builder)
+	for i := len(steps) - 1; i >= 0; i-- {
+		buf.WriteString("/" + steps[i])
+	}
+	return buf.String()
+}
+
+// qname returns the name quoted (see ParseQuery) if it would not
+// otherwise be read back as the same type name.
+func qname(name string) string {
+	plain := name != "" && name != "." && name != ".."
+	if _, err := strconv.Atoi(name); err == nil {
+		plain = false
+	}
+	for _, r := range name {
+		if !isword(r) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return name
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
+
+func selectNodes[T any](from *Node[T], q *Query, m types.Map) ([]*Node[T], error) {
+	root := from.Root()
+
+	// nil is the imaginary parent of the root when absolute
+	ctx := []*Node[T]{from}
+	if q.abs {
+		ctx = []*Node[T]{nil}
+	}
+
+	kids := func(n *Node[T]) []*Node[T] {
+		if n == nil {
+			return []*Node[T]{root}
+		}
+		return n.Nodes()
+	}
+
+	for _, s := range q.steps {
+		if s.named {
+			t, has := m[s.name]
+			if !has {
+				return nil, fmt.Errorf("unknown type name: %q", s.name)
+			}
+			s.typ = t
+		}
+
+		if s.axis == qdesc {
+			var all []*Node[T]
+			for _, c := range ctx {
+				if c == nil {
+					all = append(all, nil)
+					c = root
+				}
+				for d := range c.All() {
+					all = append(all, d)
+				}
+			}
+			ctx = all
+		}
+
+		seen := map[*Node[T]]bool{}
+		var next []*Node[T]
+		for _, c := range ctx {
+			var list []*Node[T]
+			switch s.kind {
+			case qself:
+				if c != nil {
+					list = []*Node[T]{c}
+				}
+			case qparent:
+				if c != nil && c.P != nil {
+					list = []*Node[T]{c.P}
+				}
+			default:
+				for _, k := range kids(c) {
+					if s.any || k.T == s.typ {
+						list = append(list, k)
+					}
+				}
+			}
+			for _, pr := range s.preds {
+				list = filter(list, pr)
+			}
+			for _, k := range list {
+				if !seen[k] {
+					seen[k] = true
+					next = append(next, k)
+				}
+			}
+		}
+		ctx = next
+	}
+
+	// document order
+	order := map[*Node[T]]int{}
+	var i int
+	for n := range root.All() {
+		order[n] = i
+		i++
+	}
+	sort.SliceStable(ctx, func(a, b int) bool { return order[ctx[a]] < order[ctx[b]] })
+	return ctx, nil
+}
+
+func filter[T any](list []*Node[T], pr qpred) []*Node[T] {
+	var keep []*Node[T]
+	for i, n := range list {
+		var ok bool
+		switch pr.kind {
+		case qpos:
+			pos := pr.n
+			if pos < 0 {
+				pos = len(list) + 1 + pos
+			}
+			ok = i+1 == pos
+		case qdepth:
+			ok = compare(float64(n.Depth()), float64(pr.n), pr.op)
+		case qhas:
+			ok = hasValue(n.V)
+		case qval:
+			s := valstr(n.V)
+			switch pr.op {
+			case "~":
+				ok = pr.re.MatchString(s)
+			default:
+				a, aerr := strconv.ParseFloat(s, 64)
+				b, berr := strconv.ParseFloat(pr.s, 64)
+				if aerr == nil && berr == nil {
+					ok = compare(a, b, pr.op)
+				} else {
+					ok = compare(strings.Compare(s, pr.s), 0, pr.op)
+				}
+			}
+		}
+		if ok {
+			keep = append(keep, n)
+		}
+	}
+	return keep
+}
+
+func compare[N int | float64](a, b N, op string) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// valstr returns the value as a string (avoiding fmt.Sprint where
+// possible).
+func valstr(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	case []rune:
+		return string(s)
+	default:
+		return fmt.Sprint(s)
+	}
+}
@@ -0,0 +1,145 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func program() *tree.E[any] {
+	t := tree.New[any]("Program", "Function", "Param", "Body", "Ident")
+	f := t.Root.Add(2, "main")
+	f.Add(3, "argc")
+	f.Add(3, "argv")
+	f.Add(4, nil).Add(5, "x")
+	g := t.Root.Add(2, "add")
+	g.Add(3, "a")
+	g.Add(3, "b")
+	b := g.Add(4, nil)
+	b.Add(5, "a")
+	b.Add(5, "b")
+	t.Root.Add(3, "stray")
+	return t
+}
+
+func printAll(list []*tree.Node[any], err error) {
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, n := range list {
+		fmt.Print(n.V, ";")
+	}
+	fmt.Println()
+}
+
+func ExampleE_Query() {
+	t := program()
+	printAll(t.Query(`/Program/Function`))
+	printAll(t.Query(`Function`))
+	printAll(t.Query(`//Function//Param`))
+	printAll(t.Query(`//Param`))
+	printAll(t.Query(`//Function/Param[1]`))
+	printAll(t.Query(`//Function/Param[-1]`))
+	printAll(t.Query(`//Param[V="a"]`))
+	printAll(t.Query(`//*[V~"^a"]`))
+	printAll(t.Query(`//Ident/../..`))
+	printAll(t.Query(`//Param[depth=1]`))
+	printAll(t.Query(`//*[depth>=3]`))
+	printAll(t.Query(`//Body/*[V][2]`))
+	printAll(t.Query(`//5`))
+	printAll(t.Query(`/Function`))
+	printAll(t.Query(`//Function[V!='main']/Body/.`))
+	// Output:
+	// main;add;
+	// main;add;
+	// argc;argv;a;b;
+	// argc;argv;a;b;stray;
+	// argc;a;
+	// argv;b;
+	// a;
+	// argc;argv;add;a;a;
+	// main;add;
+	// stray;
+	// x;a;b;
+	// b;
+	// x;a;b;
+	//
+	// <nil>;
+}
+
+func ExampleE_Query_errors() {
+	t := program()
+	printAll(t.Query(`//Nope`))
+	printAll(t.Query(``))
+	printAll(t.Query(`//Param[V=a]`))
+	printAll(t.Query(`//Param[0]`))
+	printAll(t.Query(`//Param[V~"("]`))
+	// Output:
+	// unknown type name: "Nope"
+	// empty query
+	// query "//Param[V=a]" at 10: expected quoted string
+	// query "//Param[0]" at 9: expected position, depth or V
+	// query "//Param[V~\"(\"]" at 13: error parsing regexp: missing closing ): `(`
+}
+
+func ExampleNode_Query() {
+	t := program()
+	add := t.Root.Last().Prev()
+	printAll(add.Query(`Param`))
+	printAll(add.Query(`//Ident`)) // absolute from the Root
+	printAll(add.Query(`.//Ident`))
+	printAll(add.Query(`..`))
+
+	// names require a tree
+	n := new(tree.Node[any])
+	n.Add(1, "one")
+	printAll(n.Query(`*`))
+	printAll(n.Query(`Thing`))
+
+	// Output:
+	// a;b;
+	// x;a;b;
+	// a;b;
+	// <nil>;
+	// one;
+	// unknown type name: "Thing"
+}
+
+func ExampleParseQuery() {
+	q, err := tree.ParseQuery(`//Function/Param[V="a"]`)
+	fmt.Println(q, err)
+	t := program()
+	printAll(t.Select(q))
+	printAll(t.Root.Select(q))
+	// Output:
+	// //Function/Param[V="a"] <nil>
+	// a;
+	// a;
+}
+
+func ExampleNode_Path() {
+	t := program()
+	list, _ := t.Query(`//Ident`)
+	for _, n := range list {
+		p := n.Path()
+		found, _ := t.Query(p)
+		fmt.Println(p, len(found) == 1 && found[0] == n)
+	}
+	fmt.Println(t.Root.Path())
+	// Output:
+	// /Program/Function[1]/Body[1]/Ident[1] true
+	// /Program/Function[2]/Body[1]/Ident[1] true
+	// /Program/Function[2]/Body[1]/Ident[2] true
+	// /Program
+}
+
+func ExampleNode_Path_quoted() {
+	t := tree.New[string]("Doc", "my type", "a/b", "42", `say "hi"`)
+	c := t.Root.Add(2, "").Add(3, "").Add(4, "").Add(5, "")
+	p := c.Path()
+	found, err := t.Query(p)
+	fmt.Println(p, err, len(found) == 1 && found[0] == c)
+	// Output:
+	// /Doc/"my type"[1]/"a/b"[1]/"42"[1]/"say \"hi\""[1] <nil> true
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RenderOpts are the options for Render. The zero value (or nil) has
+// no limits and uses Unicode box-drawing characters.
+type RenderOpts struct {
+	Depth int  // max depth below the rendered Node (0 for no limit)
+	Width int  // max runes of each value (0 for no limit)
+	ASCII bool // use only ASCII characters for lines
+}
+
+// Render returns the Root rendered as a multi-line hierarchy (see
+// Node.Render).
+func (t *E[T]) Render(o *RenderOpts) string {
+	if t.Root == nil {
+		return ""
+	}
+	return t.Root.Render(o)
+}
+
+// Render returns this Node and every Node under it rendered as
+// a multi-line hierarchy (one Node per line) drawn with box-drawing
+// characters for reading in a terminal, usually when debugging
+// a parser:
+//
+//	Grammar
+//	├── Rule "foo"
+//	│   └── Ident "x"
+//	└── Rule "bar"
+//
+// Each type is shown by its name from the Tree when there is one (or
+// its integer otherwise) followed by the value unless it is the zero
+// value for its type. Strings are quoted. Nodes that would be past the
+// Depth limit are not shown but the number of nodes directly under the
+// last one shown is added after it (ex: (+3)). Values longer than
+// Width are truncated with an ellipsis.
+func (n *Node[T]) Render(o *RenderOpts) string {
+	if o == nil {
+		o = new(RenderOpts)
+	}
+	tee, elbow, pipe, more := "├── ", "└── ", "│   ", "…"
+	if o.ASCII {
+		tee, elbow, pipe, more = "|-- ", "`-- ", "|   ", "..."
+	}
+	buf := new(strings.Builder)
+	above := []*Node[T]{}
+	n.WalkDeepFlow(func(c *Node[T], d int) Flow {
+		if d > 0 {
+			above = above[:0]
+			for a := c.P; len(above) < d-1; a = a.P {
+				above = append(above, a)
+			}
+			for i := len(above) - 1; i >= 0; i-- {
+				if above[i].right != nil {
+					buf.WriteString(pipe)
+				} else {
+					buf.WriteString("    ")
+				}
+			}
+			if c.right != nil {
+				buf.WriteString(tee)
+			} else {
+				buf.WriteString(elbow)
+			}
+		}
+		buf.WriteString(c.name())
+		if hasValue(c.V) {
+			buf.WriteString(" ")
+			buf.WriteString(renderValue(c.V, o.Width, more, o.ASCII))
+		}
+		if o.Depth > 0 && d >= o.Depth && c.Count > 0 {
+			buf.WriteString(" (+" + strconv.Itoa(c.Count) + ")\n")
+			return Skip
+		}
+		buf.WriteString("\n")
+		return Continue
+	}, nil)
+	return buf.String()
+}
+
+// name returns the type name from the Tree or the integer as a string.
+func (n *Node[T]) name() string {
+	if n.Tree != nil && n.T >= 0 && n.T < len(n.Tree.Names) {
+		return n.Tree.Names[n.T]
+	}
+	return strconv.Itoa(n.T)
+}
+
+// hasValue returns false if the value is nil or the zero value for its
+// type.
+func hasValue(v any) bool {
+	r := reflect.ValueOf(v)
+	return r.IsValid() && !r.IsZero()
+}
+
+func renderValue(v any, width int, more string, ascii bool) string {
+	s := valstr(v)
+	if width > 0 {
+		r := []rune(s)
+		if len(r) > width {
+			s = string(r[:width]) + more
+		}
+	}
+	switch v.(type) {
+	case string, []byte, []rune:
+		if ascii {
+			return strconv.QuoteToASCII(s)
+		}
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func grammar() *tree.E[any] {
+	t := tree.New[any]("Grammar", "Rule", "Ident", "Count")
+	foo := t.Root.Add(2, "foo")
+	foo.Add(3, "x")
+	foo.Add(4, 42)
+	bar := t.Root.Add(2, "bar")
+	bar.Add(3, "a rather long identifier")
+	bar.Add(3, "y").Add(9, nil)
+	return t
+}
+
+func ExampleE_Render() {
+	t := grammar()
+	fmt.Print(t.Render(nil))
+	// Output:
+	// Grammar
+	// ├── Rule "foo"
+	// │   ├── Ident "x"
+	// │   └── Count 42
+	// └── Rule "bar"
+	//     ├── Ident "a rather long identifier"
+	//     └── Ident "y"
+	//         └── 9
+}
+
+func ExampleE_Render_options() {
+	t := grammar()
+	fmt.Print(t.Render(&tree.RenderOpts{Depth: 1, Width: 2}))
+	fmt.Print(t.Render(&tree.RenderOpts{Width: 8, ASCII: true}))
+	// Output:
+	// Grammar
+	// ├── Rule "fo…" (+2)
+	// └── Rule "ba…" (+2)
+	// Grammar
+	// |-- Rule "foo"
+	// |   |-- Ident "x"
+	// |   `-- Count 42
+	// `-- Rule "bar"
+	//     |-- Ident "a rather..."
+	//     `-- Ident "y"
+	//         `-- 9
+}
+
+func ExampleNode_Render() {
+	t := grammar()
+	fmt.Print(t.Root.Last().Render(nil))
+
+	// without a tree
+	n := new(tree.Node[int])
+	n.Add(1, 0)
+	n.Add(2, 2)
+	fmt.Print(n.Render(nil))
+
+	// Output:
+	// Rule "bar"
+	// ├── Ident "a rather long identifier"
+	// └── Ident "y"
+	//     └── 9
+	// 0
+	// ├── 1
+	// └── 2 2
+}
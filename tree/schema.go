@@ -0,0 +1,165 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/rwxrob/structs/types"
+)
+
+// Schema contains the Rule for each type name (from types.Types) that
+// is to be constrained. Nodes with types that have no Rule can contain
+// anything. See Validate.
+type Schema map[string]Rule
+
+// Rule declares what is allowed under (and in) every Node of a type.
+// Only the types of Children are allowed under it (none if empty). When
+// Ordered is true the nodes under it must also be in the same order as
+// the types of Children.
+type Rule struct {
+	Value    ValueRule
+	Children []Child
+	Ordered  bool
+}
+
+// Child declares a type allowed under another and how many of them are
+// allowed. A Max of 0 means there is no limit.
+type Child struct {
+	Type     string
+	Min, Max int
+}
+
+// ValueRule declares whether a Node carries a value (V) that is not
+// the zero value for its type.
+type ValueRule int
+
+const (
+	ValueOptional  ValueRule = iota // may have a value or not
+	ValueRequired                   // must have a value
+	ValueForbidden                  // must not have a value
+)
+
+// Violation is a single failure to follow a Schema with the Path (see
+// Node.Path) of the offending Node.
+type Violation struct {
+	Path string
+	Msg  string
+}
+
+// Error implements error.
+func (v Violation) Error() string { return v.Path + ": " + v.Msg }
+
+// Check returns an error for every type name (of a Rule or Child) in
+// the Schema that is not in the Types (or an integer), which is almost
+// always a typo that would otherwise go unnoticed. See Validate.
+func (s Schema) Check(t types.Types) error {
+	var errs []error
+	for _, msg := range s.unknown(t.Map) {
+		errs = append(errs, errors.New(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// unknown returns a message for every unknown type name in order.
+func (s Schema) unknown(m types.Map) []string {
+	known := func(name string) bool {
+		if _, has := m[name]; has {
+			return true
+		}
+		_, err := strconv.Atoi(name)
+		return err == nil
+	}
+	var msgs []string
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		if !known(name) {
+			msgs = append(msgs, fmt.Sprintf("unknown type in schema: %q", name))
+		}
+		for _, ch := range s[name].Children {
+			if !known(ch.Type) {
+				msgs = append(msgs, fmt.Sprintf("unknown type in schema under %v: %q", name, ch.Type))
+			}
+		}
+	}
+	return msgs
+}
+
+// Validate returns every Violation of the Schema by the Root and every
+// Node under it (in document order) or nil if there are none.
+func (t *E[T]) Validate(s Schema) []Violation {
+	if t.Root == nil {
+		return nil
+	}
+	return t.Root.Validate(s)
+}
+
+// Validate returns every Violation of the Schema by this Node and every
+// Node under it (in document order) or nil if there are none. Types
+// are looked up by name from the Tree (or as integers without one).
+// Any type names in the Schema that are not in the Types of the Tree
+// (see Schema.Check) are reported first as violations by this Node.
+func (n *Node[T]) Validate(s Schema) []Violation {
+	var list []Violation
+	report := func(c *Node[T], format string, a ...any) {
+		list = append(list, Violation{c.Path(), fmt.Sprintf(format, a...)})
+	}
+	var m types.Map
+	if n.Tree != nil {
+		m = n.Tree.Map
+	}
+	for _, msg := range s.unknown(m) {
+		report(n, "%v", msg)
+	}
+	for c := range n.All() {
+		name := c.name()
+		rule, has := s[name]
+		if !has {
+			continue
+		}
+
+		switch v := hasValue(c.V); {
+		case rule.Value == ValueRequired && !v:
+			report(c, "%v requires a value", name)
+		case rule.Value == ValueForbidden && v:
+			report(c, "%v cannot have a value", name)
+		}
+
+		index := map[string]int{}
+		for i, ch := range rule.Children {
+			index[ch.Type] = i
+		}
+		counts := map[string]int{}
+		last := 0
+		for u := c.first; u != nil; u = u.right {
+			uname := u.name()
+			i, allowed := index[uname]
+			if !allowed {
+				report(u, "%v not allowed under %v", uname, name)
+				continue
+			}
+			counts[uname]++
+			if rule.Ordered {
+				if i < last {
+					report(u, "%v out of order under %v", uname, name)
+				} else {
+					last = i
+				}
+			}
+		}
+
+		for _, ch := range rule.Children {
+			switch num := counts[ch.Type]; {
+			case num < ch.Min:
+				report(c, "%v requires at least %v %v (found %v)", name, ch.Min, ch.Type, num)
+			case ch.Max > 0 && num > ch.Max:
+				report(c, "%v allows at most %v %v (found %v)", name, ch.Max, ch.Type, num)
+			}
+		}
+	}
+	return list
+}
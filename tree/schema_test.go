@@ -0,0 +1,64 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleE_Validate() {
+	t := tree.New[any]("Document", "Title", "Section", "Para", "Param")
+	s := tree.Schema{
+		"Document": {
+			Value:    tree.ValueForbidden,
+			Ordered:  true,
+			Children: []tree.Child{{"Title", 1, 1}, {"Section", 0, 0}},
+		},
+		"Title":   {Value: tree.ValueRequired},
+		"Section": {Children: []tree.Child{{"Para", 1, 0}}},
+		"Para":    {Value: tree.ValueRequired},
+	}
+
+	// valid
+	t.Root.Add(2, "A Title")
+	t.Root.Add(3, nil).Add(4, "some text")
+	fmt.Println(t.Validate(s))
+
+	// now break it every way
+	t.Root.V = "oops"
+	t.Root.Add(2, nil)
+	t.Root.Add(3, nil)
+	t.Root.Add(5, "p").Add(9, nil)
+	t.Root.First().Next().Add(4, nil)
+
+	for _, v := range t.Validate(s) {
+		fmt.Println(v)
+	}
+
+	// Output:
+	// []
+	// /Document: Document cannot have a value
+	// /Document/Title[2]: Title out of order under Document
+	// /Document/Param[1]: Param not allowed under Document
+	// /Document: Document allows at most 1 Title (found 2)
+	// /Document/Section[1]/Para[2]: Para requires a value
+	// /Document/Title[2]: Title requires a value
+	// /Document/Section[2]: Section requires at least 1 Para (found 0)
+}
+
+func ExampleSchema_Check() {
+	t := tree.New[any]("Document", "Title")
+	s := tree.Schema{
+		"Dco":   {},
+		"Title": {Children: []tree.Child{{"Titel", 0, 1}, {"1", 0, 0}}},
+	}
+	fmt.Println(s.Check(t.Types))
+	for _, v := range t.Validate(s) {
+		fmt.Println(v)
+	}
+	// Output:
+	// unknown type in schema: "Dco"
+	// unknown type in schema under Title: "Titel"
+	// /Document: unknown type in schema: "Dco"
+	// /Document: unknown type in schema under Title: "Titel"
+}
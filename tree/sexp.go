@@ -0,0 +1,337 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	json "github.com/rwxrob/json/pkg"
+	"github.com/rwxrob/structs/types"
+)
+
+// S-expressions are a compact, diff-friendly alternative to JSON for
+// test fixtures and documentation. Every Node is a list beginning with
+// its type name (or integer) followed by its value (unless it is the
+// zero value) and then every Node under it:
+//
+//	(Grammar (Rule "foo") (Rule "bar" (Ident "x")))
+//
+// Values are written as JSON (strings are quoted with JSON escapes,
+// numbers and booleans are bare). Anything after a semicolon (;) to the
+// end of a line is a comment when parsing.
+
+// MarshalSexp returns the Node (and every Node under it) as an
+// S-expression using the type names from the Tree (if any).
+func (s Node[T]) MarshalSexp() ([]byte, error) {
+	var names types.Names
+	if s.Tree != nil {
+		names = s.Tree.Names
+	}
+	return sexp(&s, names)
+}
+
+// MarshalSexp returns the Root as an S-expression using the type names
+// from Types. Note that the Types themselves are not included.
+func (s E[T]) MarshalSexp() ([]byte, error) {
+	if s.Root == nil {
+		return nil, nil
+	}
+	return sexp(s.Root, s.Names)
+}
+
+// sexp returns n as an S-expression using the names for any types
+// that have one that can be written as a symbol.
+func sexp[T any](n *Node[T], names types.Names) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	var err error
+	enter := func(c *Node[T], d int) Flow {
+		if d > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteByte('(')
+		name := strconv.Itoa(c.T)
+		if c.T >= 0 && c.T < len(names) && issymbol(names[c.T]) {
+			name = names[c.T]
+		}
+		buf.WriteString(name)
+		if hasValue(c.V) {
+			var v []byte
+			if v, err = json.Marshal(c.V); err != nil {
+				return Stop
+			}
+			buf.WriteByte(' ')
+			buf.Write(v)
+		}
+		return Continue
+	}
+	leave := func(c *Node[T], _ int) Flow {
+		buf.WriteByte(')')
+		return Continue
+	}
+	n.WalkDeepFlow(enter, leave)
+	return buf.Bytes(), err
+}
+
+// issymbol returns true if the name can be written as a type name
+// symbol without being confused with an integer, value, or syntax.
+func issymbol(name string) bool {
+	if name == "" || strings.ContainsAny(name, `()";`) {
+		return false
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalSexp replaces the Root with the single S-expression (see
+// MarshalSexp) looking up any type names from Types.
+func (s *E[T]) UnmarshalSexp(buf []byte) error {
+	d := NewSexpDecoder(bytes.NewReader(buf), s)
+	n, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if _, err := d.s.next(); err != io.EOF {
+		return fmt.Errorf("unexpected data after S-expression")
+	}
+	s.Root = n
+	return nil
+}
+
+// UnmarshalSexp is the inverse of MarshalSexp replacing this Node (but
+// not its place among others) with the single S-expression. Type names
+// are looked up from the Tree (which is required to use them).
+func (s *Node[T]) UnmarshalSexp(buf []byte) error {
+	d := NewSexpDecoder(bytes.NewReader(buf), s.Tree)
+	n, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if _, err := d.s.next(); err != io.EOF {
+		return fmt.Errorf("unexpected data after S-expression")
+	}
+	s.T = n.T
+	s.V = n.V
+	s.discard()
+	s.Take(n)
+	return nil
+}
+
+// SexpDecoder reads S-expressions (see MarshalSexp) one at a time from
+// a stream without holding more than the current one in memory.
+type SexpDecoder[T any] struct {
+	s    *sexpScanner
+	tree *E[T]
+}
+
+// NewSexpDecoder returns a decoder that reads from r and attaches every
+// new Node to the given tree (which may be nil) using its Types to look
+// up type names.
+func NewSexpDecoder[T any](r io.Reader, t *E[T]) *SexpDecoder[T] {
+	return &SexpDecoder[T]{s: newSexpScanner(r), tree: t}
+}
+
+// Decode returns the next complete S-expression as a new Node (and
+// those under it) or io.EOF when there are no more.
+func (d *SexpDecoder[T]) Decode() (*Node[T], error) {
+	var m types.Map
+	if d.tree != nil {
+		m = d.tree.Map
+	}
+	var cur, top *Node[T]
+	for {
+		tok, err := d.s.next()
+		if err != nil {
+			if err == io.EOF && cur != nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch tok.kind {
+
+		case '(':
+			tok, err = d.s.next()
+			if err != nil {
+				return nil, d.s.unexpected(err)
+			}
+			if tok.kind != 'a' {
+				return nil, d.s.errorf("expected type")
+			}
+			n := new(Node[T])
+			n.Tree = d.tree
+			if n.T, err = sexpType(tok.text, m); err != nil {
+				return nil, d.s.errorf("%v", err)
+			}
+			next, err := d.s.peek()
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if err == nil && (next.kind == 'a' || next.kind == 's') {
+				d.s.next()
+				if err := json.Unmarshal([]byte(next.text), &n.V); err != nil {
+					return nil, d.s.errorf("invalid value %v: %v", next.text, err)
+				}
+			}
+			if cur == nil {
+				top = n
+			} else {
+				cur.Append(n)
+			}
+			cur = n
+
+		case ')':
+			if cur == nil {
+				return nil, d.s.errorf("unexpected )")
+			}
+			if cur == top {
+				return top, nil
+			}
+			cur = cur.P
+
+		default:
+			return nil, d.s.errorf("unexpected %v", tok.text)
+		}
+	}
+}
+
+func sexpType(name string, m types.Map) (int, error) {
+	if n, err := strconv.Atoi(name); err == nil {
+		return n, nil
+	}
+	n, has := m[name]
+	if !has {
+		return 0, fmt.Errorf("unknown type name: %q", name)
+	}
+	return n, nil
+}
+
+// ----------------------------- scanning -----------------------------
+
+type sexpTok struct {
+	kind rune   // ( ) a (atom) s (string)
+	text string // strings include quotes and escapes
+}
+
+type sexpScanner struct {
+	r      *bufio.Reader
+	line   int
+	peeked *sexpTok
+}
+
+func newSexpScanner(r io.Reader) *sexpScanner {
+	return &sexpScanner{r: bufio.NewReader(r), line: 1}
+}
+
+func (s *sexpScanner) errorf(format string, a ...any) error {
+	return fmt.Errorf("line %v: %v", s.line, fmt.Sprintf(format, a...))
+}
+
+func (s *sexpScanner) unexpected(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (s *sexpScanner) peek() (sexpTok, error) {
+	if s.peeked == nil {
+		tok, err := s.scan()
+		if err != nil {
+			return tok, err
+		}
+		s.peeked = &tok
+	}
+	return *s.peeked, nil
+}
+
+func (s *sexpScanner) next() (sexpTok, error) {
+	if s.peeked != nil {
+		tok := *s.peeked
+		s.peeked = nil
+		return tok, nil
+	}
+	return s.scan()
+}
+
+func (s *sexpScanner) scan() (sexpTok, error) {
+	var tok sexpTok
+	for {
+		r, _, err := s.r.ReadRune()
+		if err != nil {
+			return tok, err
+		}
+		switch {
+		case r == '\n':
+			s.line++
+		case unicode.IsSpace(r):
+		case r == ';':
+			if _, err := s.r.ReadString('\n'); err != nil {
+				return tok, err
+			}
+			s.line++
+		case r == '(' || r == ')':
+			tok.kind = r
+			tok.text = string(r)
+			return tok, nil
+		case r == '"':
+			return s.quoted()
+		default:
+			buf := []rune{r}
+			for {
+				r, _, err := s.r.ReadRune()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return tok, err
+				}
+				if unicode.IsSpace(r) || strings.ContainsRune(`()";`, r) {
+					s.r.UnreadRune()
+					break
+				}
+				buf = append(buf, r)
+			}
+			tok.kind = 'a'
+			tok.text = string(buf)
+			return tok, nil
+		}
+	}
+}
+
+func (s *sexpScanner) quoted() (sexpTok, error) {
+	tok := sexpTok{kind: 's'}
+	buf := []rune{'"'}
+	for {
+		r, _, err := s.r.ReadRune()
+		if err != nil {
+			return tok, s.errorf("unterminated string")
+		}
+		buf = append(buf, r)
+		switch r {
+		case '\n':
+			s.line++
+		case '\\':
+			r, _, err = s.r.ReadRune()
+			if err != nil {
+				return tok, s.errorf("unterminated string")
+			}
+			buf = append(buf, r)
+		case '"':
+			tok.text = string(buf)
+			return tok, nil
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tree_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+func ExampleE_MarshalSexp() {
+	t := tree.New[any]("Grammar", "Rule", "Ident")
+	t.Root.Add(2, "foo")
+	bar := t.Root.Add(2, "bar")
+	bar.Add(3, `say "hi"`+"\n")
+	bar.Add(9, 4.2)
+	bar.Add(3, true)
+	buf, err := t.MarshalSexp()
+	fmt.Println(string(buf), err)
+
+	// nodes without a tree use integers
+	n := new(tree.Node[int])
+	n.Add(1, 0).Add(2, 3)
+	buf, _ = n.MarshalSexp()
+	fmt.Println(string(buf))
+
+	// Output:
+	// (Grammar (Rule "foo") (Rule "bar" (Ident "say \"hi\"\n") (9 4.2) (Ident true))) <nil>
+	// (0 (1 (2 3)))
+}
+
+func ExampleE_UnmarshalSexp() {
+	t := tree.New[any]("Grammar", "Rule", "Ident")
+	err := t.UnmarshalSexp([]byte(`
+	  ; fixtures can have comments
+	  (Grammar
+	    (Rule "foo")
+	    (Rule "bar" (Ident "say \"hi\"") (9 4.2)))
+	`))
+	fmt.Println(err)
+	t.Root.Print()
+	fmt.Println(t.Root.Count, t.Root.Last().Last().P == t.Root.Last(), t.Root.Last().Tree == t)
+
+	// round trip
+	buf, _ := t.MarshalSexp()
+	u := tree.New[any]("Grammar", "Rule", "Ident")
+	u.UnmarshalSexp(buf)
+	fmt.Println(u.String() == t.String())
+
+	// Output:
+	// <nil>
+	// {"T":1,"N":[{"T":2,"V":"foo"},{"T":2,"V":"bar","N":[{"T":3,"V":"say \"hi\""},{"T":9,"V":4.2}]}]}
+	// 2 true true
+	// true
+}
+
+func ExampleNode_UnmarshalSexp() {
+	n := new(tree.Node[int])
+	err := n.UnmarshalSexp([]byte(`(1 42 (2) (3 -1))`))
+	fmt.Println(err)
+	n.Print()
+
+	// nodes that were under it are detached
+	old := n.First()
+	n.UnmarshalSexp([]byte(`(1 (4))`))
+	n.Append(old)
+	n.Print()
+	fmt.Println(n.Count)
+
+	// Output:
+	// <nil>
+	// {"T":1,"V":42,"N":[{"T":2},{"T":3,"V":-1}]}
+	// {"T":1,"N":[{"T":4},{"T":2}]}
+	// 2
+}
+
+func ExampleSexpDecoder() {
+	t := tree.New[string]("Doc", "Word")
+	in := strings.NewReader(`(Word "one") (Word "two")
+	(Doc (Word "three"))`)
+	d := tree.NewSexpDecoder(in, t)
+	for {
+		n, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		n.Print()
+	}
+	// Output:
+	// {"T":2,"V":"one"}
+	// {"T":2,"V":"two"}
+	// {"T":1,"N":[{"T":2,"V":"three"}]}
+}
+
+func ExampleSexpDecoder_errors() {
+	t := tree.New[int]("Doc")
+	for _, in := range []string{
+		`(Doc`, `(Nope)`, `(Doc "str")`, `)`, `(Doc "open`, `(Doc) extra`, `("Doc")`,
+	} {
+		fmt.Println(t.UnmarshalSexp([]byte(in)))
+	}
+	// Output:
+	// unexpected EOF
+	// line 1: unknown type name: "Nope"
+	// line 1: invalid value "str": json: cannot unmarshal string into Go value of type int
+	// line 1: unexpected )
+	// line 1: unterminated string
+	// unexpected data after S-expression
+	// line 1: expected type
+}
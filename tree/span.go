@@ -0,0 +1,100 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json/pkg"
+)
+
+// Pos is a position within source text (usually that which was parsed
+// into a tree). Off is the byte offset from the start (beginning with
+// 0). Line and Col begin with 1 (as most editors and compilers do)
+// and Col counts bytes (not runes) from the start of the line.
+type Pos struct {
+	Off  int
+	Line int
+	Col  int
+}
+
+// String implements fmt.Stringer as line:col (ex: 3:14).
+func (p Pos) String() string { return fmt.Sprintf("%v:%v", p.Line, p.Col) }
+
+// Span is the range of source text from which a Node was parsed. End is
+// the first position after the Node (so a Span with the same Beg and
+// End is empty). Nodes have no Span (S) unless set explicitly (usually
+// by a parser). A Span is marshaled as a compact JSON array of six
+// integers (offset, line, and column of Beg then End).
+type Span struct {
+	Beg Pos
+	End Pos
+}
+
+// String implements fmt.Stringer as line:col-line:col (ex: 1:1-2:10).
+func (s Span) String() string { return s.Beg.String() + "-" + s.End.String() }
+
+// Covers returns true if the offset is within the Span (from Beg.Off up
+// to but not including End.Off).
+func (s Span) Covers(off int) bool { return off >= s.Beg.Off && off < s.End.Off }
+
+// MarshalJSON implements encoding/json.Marshaler.
+func (s Span) MarshalJSON() ([]byte, error) {
+	return json.Marshal([6]int{
+		s.Beg.Off, s.Beg.Line, s.Beg.Col,
+		s.End.Off, s.End.Line, s.End.Col,
+	})
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler.
+func (s *Span) UnmarshalJSON(buf []byte) error {
+	var a [6]int
+	if err := json.Unmarshal(buf, &a); err != nil {
+		return err
+	}
+	s.Beg = Pos{a[0], a[1], a[2]}
+	s.End = Pos{a[3], a[4], a[5]}
+	return nil
+}
+
+func (s *Span) copy() *Span {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// At returns the innermost Node (under and including the Root) with
+// a Span covering the byte offset (or nil if there are none). See
+// Node.At.
+func (t *E[T]) At(off int) *Node[T] {
+	if t.Root == nil {
+		return nil
+	}
+	return t.Root.At(off)
+}
+
+// At returns the innermost Node (under and including this one) with
+// a Span covering the byte offset (or nil if there are none). Nodes
+// without a Span are passed through (as if they covered everything)
+// but are never returned. When siblings overlap the first wins (and
+// nothing under those after it is ever returned).
+func (n *Node[T]) At(off int) *Node[T] {
+	var found *Node[T]
+	n.WalkDeepFlow(func(c *Node[T], _ int) Flow {
+		if found != nil && !c.within(found) {
+			return Stop // past everything under the first to cover it
+		}
+		if c.S == nil {
+			return Continue
+		}
+		if !c.S.Covers(off) {
+			return Skip
+		}
+		found = c
+		return Continue
+	}, nil)
+	return found
+}
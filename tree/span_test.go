@@ -0,0 +1,79 @@
+package tree_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/structs/tree"
+)
+
+// source is the text the spans in spanned refer to
+const source = "foo(bar, 42)"
+
+func spanned() *tree.E[string] {
+	t := tree.New[string]("Call", "Ident", "Args", "Num")
+	span := func(beg, end int) *tree.Span {
+		return &tree.Span{tree.Pos{beg, 1, beg + 1}, tree.Pos{end, 1, end + 1}}
+	}
+	t.Root.S = span(0, 12)
+	t.Root.Add(2, "foo").S = span(0, 3)
+	args := t.Root.Add(3, "")
+	args.S = span(3, 12)
+	args.Add(2, "bar").S = span(4, 7)
+	args.Add(4, "42").S = span(9, 11)
+	return t
+}
+
+func ExampleNode_At() {
+	t := spanned()
+	for _, off := range []int{0, 3, 5, 8, 10, 11, 12} {
+		n := t.At(off)
+		if n == nil {
+			fmt.Println(off, "nothing")
+			continue
+		}
+		fmt.Printf("%v %v %v %q\n", off, t.Names[n.T], n.S, source[n.S.Beg.Off:n.S.End.Off])
+	}
+	// Output:
+	// 0 Ident 1:1-1:4 "foo"
+	// 3 Args 1:4-1:13 "(bar, 42)"
+	// 5 Ident 1:5-1:8 "bar"
+	// 8 Args 1:4-1:13 "(bar, 42)"
+	// 10 Num 1:10-1:12 "42"
+	// 11 Args 1:4-1:13 "(bar, 42)"
+	// 12 nothing
+}
+
+func ExampleNode_At_overlap() {
+	t := tree.New[string]("Doc", "Word", "Char")
+	t.Root.S = &tree.Span{End: tree.Pos{10, 1, 11}}
+	a := t.Root.Add(2, "a")
+	a.S = &tree.Span{Beg: tree.Pos{0, 1, 1}, End: tree.Pos{6, 1, 7}}
+	b := t.Root.Add(2, "b")
+	b.S = &tree.Span{Beg: tree.Pos{4, 1, 5}, End: tree.Pos{10, 1, 11}}
+	b.Add(3, "c").S = &tree.Span{Beg: tree.Pos{4, 1, 5}, End: tree.Pos{5, 1, 6}}
+
+	// the first sibling wins even with something deeper under the next
+	fmt.Println(t.At(4).V, t.At(7).V)
+
+	// Output:
+	// a b
+}
+
+func ExampleSpan_json() {
+	t := spanned()
+	n := t.Root.Last().Last()
+	n.Print()
+
+	c := n.Copy()
+	c.S.Beg.Col = 99
+	fmt.Println(n.S, c.S)
+
+	u := t.Node(0, "")
+	u.UnmarshalJSON([]byte(`{"T":4,"V":"1","S":[2,1,3,3,1,4]}`))
+	fmt.Println(u.S)
+
+	// Output:
+	// {"T":4,"V":"42","S":[9,1,10,11,1,12]}
+	// 1:10-1:12 1:99-1:12
+	// 1:3-1:4
+}
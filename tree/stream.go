@@ -0,0 +1,363 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"bufio"
+	std "encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	json "github.com/rwxrob/json/pkg"
+	"github.com/rwxrob/structs/types"
+)
+
+// ----------------------------- encoding -----------------------------
+
+// EncodeJSON writes the tree as JSON (exactly the same as MarshalJSON)
+// to the writer one Node at a time so that no more than a single value
+// is ever held in memory. See DecodeJSON.
+func EncodeJSON[T any](w io.Writer, t *E[T]) error {
+	out := bufio.NewWriter(w)
+	out.WriteByte('{')
+	sep := ""
+	if len(t.Names) > 0 {
+		out.WriteString(`"Names":`)
+		if err := writeJSON(out, t.Names); err != nil {
+			return err
+		}
+		sep = ","
+	}
+	if len(t.Map) > 0 {
+		out.WriteString(sep + `"Map":`)
+		if err := writeJSON(out, t.Map); err != nil {
+			return err
+		}
+		sep = ","
+	}
+	if t.Root != nil {
+		out.WriteString(sep + `"Root":`)
+		if err := encodeNodes(out, t.Root); err != nil {
+			return err
+		}
+	}
+	out.WriteByte('}')
+	return out.Flush()
+}
+
+// EncodeNodeJSON is the same as EncodeJSON but writes only the Node
+// (and those under it) exactly the same as Node.MarshalJSON.
+func EncodeNodeJSON[T any](w io.Writer, n *Node[T]) error {
+	out := bufio.NewWriter(w)
+	if err := encodeNodes(out, n); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func encodeNodes[T any](out *bufio.Writer, n *Node[T]) error {
+	var err error
+	enter := func(c *Node[T], _ int) Flow {
+		if c != n && c.left != nil {
+			out.WriteByte(',')
+		}
+		fmt.Fprintf(out, `{"T":%d`, c.T)
+		if !isEmptyJSON(c.V) {
+			out.WriteString(`,"V":`)
+			if err = writeJSON(out, c.V); err != nil {
+				return Stop
+			}
+		}
+		if c.S != nil {
+			out.WriteString(`,"S":`)
+			if err = writeJSON(out, c.S); err != nil {
+				return Stop
+			}
+		}
+		if c.first != nil {
+			out.WriteString(`,"N":[`)
+		}
+		return Continue
+	}
+	leave := func(c *Node[T], _ int) Flow {
+		if c.first != nil {
+			out.WriteByte(']')
+		}
+		out.WriteByte('}')
+		return Continue
+	}
+	n.WalkDeepFlow(enter, leave)
+	return err
+}
+
+// isEmptyJSON returns true for the same values as the omitempty JSON
+// struct tag.
+func isEmptyJSON(v any) bool {
+	if v == nil {
+		return true
+	}
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return r.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return r.IsZero()
+	}
+	return false
+}
+
+// ----------------------------- decoding -----------------------------
+
+// Handler contains the functions called by DecodeJSON (any of which
+// may be nil). Types is called once before the Root with the Types
+// (rebuilding the Map from the Names if needed). Enter is called at
+// the start of every Node with its type and depth (0 for the Root),
+// then Value (if it has one) and Span (if it has one), then the same
+// for every Node under it (in order), and finally Leave. Returning an
+// error from any of them stops decoding and is returned by DecodeJSON.
+type Handler[T any] struct {
+	Types func(t types.Types) error
+	Enter func(typ, depth int) error
+	Value func(v T) error
+	Span  func(s Span) error
+	Leave func(typ, depth int) error
+}
+
+// DecodeJSON reads the JSON of either a tree (see MarshalJSON and
+// MarshalJSONLong) or a single Node (see Node.MarshalJSON) from the
+// reader calling the functions of the Handler (SAX-style) for every
+// part of every Node as it is read so that no more than a single value
+// is ever held in memory. Since nothing can be called until the type of
+// a Node is known, T must be the first (if any) in every Node (as it is
+// when written by any of the encoders in this package). Types in the
+// long form (names) are looked up from the Types (which must come
+// before the Root). A null Root is the same as none (an empty tree).
+func DecodeJSON[T any](r io.Reader, h Handler[T]) error {
+	dec := std.NewDecoder(r)
+	d := &jsonDecoder[T]{dec: dec, h: h}
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != std.Delim('{') {
+		return fmt.Errorf("expected object: %v", tok)
+	}
+	var tt types.Types
+	var done, rooted bool
+	for !done {
+		tok, err := dec.Token()
+		if err != nil {
+			return d.unexpected(err)
+		}
+		switch tok {
+		case std.Delim('}'):
+			done = true
+		case "Names":
+			err = dec.Decode(&tt.Names)
+		case "Map":
+			err = dec.Decode(&tt.Map)
+		case "Root":
+			if rooted {
+				return fmt.Errorf("more than one Root")
+			}
+			rooted = true
+			if err = d.types(&tt); err != nil {
+				return err
+			}
+			if tok, err = dec.Token(); err != nil {
+				return d.unexpected(err)
+			}
+			if tok != nil { // null is an empty tree
+				err = d.nodes(tt.Map, []any{tok})
+			}
+		case "T":
+			d.typed = true // just a Node
+			err = d.nodes(tt.Map, []any{std.Delim('{'), "T"})
+			done = true
+		default:
+			err = fmt.Errorf("unexpected %v", tok)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := d.types(&tt); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+type jsonDecoder[T any] struct {
+	dec   *std.Decoder
+	h     Handler[T]
+	typed bool // if Types has been called
+}
+
+func (d *jsonDecoder[T]) unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// types calls Handler.Types (only once) rebuilding Map if needed.
+func (d *jsonDecoder[T]) types(tt *types.Types) error {
+	if d.typed {
+		return nil
+	}
+	d.typed = true
+	if len(tt.Map) == 0 && len(tt.Names) > 0 {
+		tt.Map = types.Map{}
+		for n, v := range tt.Names {
+			tt.Map[v] = n
+		}
+	}
+	if d.h.Types == nil {
+		return nil
+	}
+	return d.h.Types(*tt)
+}
+
+// just for tracking each Node object while decoding
+type jsonFrame struct {
+	typ     int
+	entered bool // if Enter has been called
+	list    bool // if inside of N
+}
+
+// nodes decodes a Node object (and every one under it) after first
+// using the tokens passed as if they had been read.
+func (d *jsonDecoder[T]) nodes(m types.Map, read []any) error {
+	var stack []*jsonFrame
+	enter := func(f *jsonFrame) error {
+		f.entered = true
+		if d.h.Enter == nil {
+			return nil
+		}
+		return d.h.Enter(f.typ, len(stack)-1)
+	}
+
+	for {
+		var tok any
+		var err error
+		if len(read) > 0 {
+			tok, read = read[0], read[1:]
+		} else if tok, err = d.dec.Token(); err != nil {
+			return d.unexpected(err)
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch tok {
+
+		case std.Delim('{'):
+			if top != nil && !top.list {
+				return fmt.Errorf("unexpected {")
+			}
+			stack = append(stack, new(jsonFrame))
+			continue
+
+		case std.Delim('}'):
+			if top == nil || top.list {
+				return fmt.Errorf("unexpected }")
+			}
+			if !top.entered {
+				if err := enter(top); err != nil {
+					return err
+				}
+			}
+			if d.h.Leave != nil {
+				if err := d.h.Leave(top.typ, len(stack)-1); err != nil {
+					return err
+				}
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return nil
+			}
+			continue
+
+		case std.Delim(']'):
+			if top == nil || !top.list {
+				return fmt.Errorf("unexpected ]")
+			}
+			top.list = false
+			continue
+		}
+
+		key, is := tok.(string)
+		if !is || top == nil || top.list {
+			return fmt.Errorf("unexpected %v", tok)
+		}
+		if key == "T" {
+			if top.entered {
+				return fmt.Errorf("T must be first")
+			}
+			var raw std.RawMessage
+			if err := d.dec.Decode(&raw); err != nil {
+				return err
+			}
+			if top.typ, err = typeFromJSON(raw, m); err != nil {
+				return err
+			}
+			if err := enter(top); err != nil {
+				return err
+			}
+			continue
+		}
+		if !top.entered {
+			if err := enter(top); err != nil {
+				return err
+			}
+		}
+
+		switch key {
+		case "V":
+			var raw std.RawMessage
+			if err := d.dec.Decode(&raw); err != nil {
+				return err
+			}
+			var v T
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			if d.h.Value != nil {
+				err = d.h.Value(v)
+			}
+		case "S":
+			var s Span
+			if err := d.dec.Decode(&s); err != nil {
+				return err
+			}
+			if d.h.Span != nil {
+				err = d.h.Span(s)
+			}
+		case "N":
+			tok, err := d.dec.Token()
+			if err != nil {
+				return d.unexpected(err)
+			}
+			if tok != std.Delim('[') {
+				return fmt.Errorf("expected array: %v", tok)
+			}
+			top.list = true
+		default:
+			err = fmt.Errorf("unexpected %v", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package tree_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+	"github.com/rwxrob/structs/types"
+)
+
+func ExampleEncodeJSON() {
+	t := grammar()
+	t.Root.Last().S = &tree.Span{End: tree.Pos{10, 2, 1}}
+	buf := new(bytes.Buffer)
+	fmt.Println(tree.EncodeJSON(buf, t))
+	js, _ := t.MarshalJSON()
+	fmt.Println(buf.String() == string(js))
+
+	tree.EncodeNodeJSON(os.Stdout, t.Root.Last())
+	fmt.Println()
+
+	// Output:
+	// <nil>
+	// true
+	// {"T":2,"V":"bar","S":[0,0,0,10,2,1],"N":[{"T":3,"V":"a rather long identifier"},{"T":3,"V":"y","N":[{"T":9}]}]}
+}
+
+func ExampleDecodeJSON() {
+	var names types.Names
+	h := tree.Handler[any]{
+		Types: func(t types.Types) error {
+			names = t.Names
+			fmt.Println("types", t.Names)
+			return nil
+		},
+		Enter: func(typ, depth int) error {
+			fmt.Printf("%venter %v\n", strings.Repeat("  ", depth), names[typ])
+			return nil
+		},
+		Value: func(v any) error {
+			fmt.Printf("value %q\n", v)
+			return nil
+		},
+		Leave: func(typ, depth int) error {
+			fmt.Printf("%vleave %v\n", strings.Repeat("  ", depth), names[typ])
+			return nil
+		},
+	}
+
+	// long form has type names and no Map
+	in := `{"Names":["UNKNOWN","Doc","Para","Word"],"Root":{"T":"Doc","N":[
+		{"T":"Para","N":[{"T":"Word","V":"hello"},{"T":"Word","V":"there"}]}
+	]}}`
+	fmt.Println(tree.DecodeJSON(strings.NewReader(in), h))
+
+	// Output:
+	// types ["UNKNOWN","Doc","Para","Word"]
+	// enter Doc
+	//   enter Para
+	//     enter Word
+	// value "hello"
+	//     leave Word
+	//     enter Word
+	// value "there"
+	//     leave Word
+	//   leave Para
+	// leave Doc
+	// <nil>
+}
+
+func ExampleDecodeJSON_count() {
+	// counting nodes without building a tree
+	t := grammar()
+	buf := new(bytes.Buffer)
+	tree.EncodeJSON(buf, t)
+	var count, deepest int
+	err := tree.DecodeJSON(buf, tree.Handler[any]{
+		Enter: func(typ, depth int) error {
+			count++
+			deepest = max(deepest, depth)
+			return nil
+		},
+	})
+	fmt.Println(count, deepest, err)
+
+	for _, in := range []string{
+		`{"T":1,"N":[{"T":2}]}`,
+		`{"T":1,"N":[{"V":"x","T":2}]}`,
+		`{"T":1,"N":[{"T":2}]`,
+		`{"Root":{"T":"Doc"}}`,
+		`{"T":1}{}`,
+		`[]`,
+		`{"Names":["UNKNOWN","Doc"],"Root":null}`,
+		`{"Root":{"T":1},"Root":{"T":1}}`,
+		`{"Root":1}`,
+	} {
+		fmt.Println(tree.DecodeJSON(strings.NewReader(in), tree.Handler[string]{}))
+	}
+
+	// Output:
+	// 8 3 <nil>
+	// <nil>
+	// T must be first
+	// unexpected EOF
+	// unknown type name: "Doc"
+	// unexpected data after JSON value
+	// expected object: [
+	// <nil>
+	// more than one Root
+	// unexpected 1
+}
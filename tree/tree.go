@@ -4,6 +4,7 @@
 package tree
 
 import (
+	std "encoding/json"
 	"fmt"
 	"log"
 
@@ -68,12 +69,69 @@ func (s E[T]) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t)
 }
 
+// just for long form marshaling
+type jsltree[T any] struct {
+	Names types.Names `json:",omitempty"`
+	Root  *jslnode[T] `json:",omitempty"`
+}
+
+func (s E[T]) long() *jsltree[T] {
+	t := new(jsltree[T])
+	t.Names = s.Names
+	if s.Root != nil {
+		t.Root = s.Root.long(s.Names)
+	}
+	return t
+}
+
+// MarshalJSONLong is the same as MarshalJSON but every Node type is
+// written as its string name from Types.Names instead of an integer
+// (unless no such name exists). The Types.Map is omitted since it is
+// rebuilt from the Names when unmarshaled.
+func (s E[T]) MarshalJSONLong() ([]byte, error) {
+	return json.Marshal(s.long())
+}
+
+// just for unmarshaling either form
+type jsintree struct {
+	Names types.Names
+	Map   types.Map
+	Root  std.RawMessage
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler and is the inverse
+// of both MarshalJSON and MarshalJSONLong (node types may be either
+// integers or names). If only the Types.Names are found the Types.Map
+// is rebuilt from them. Every Node under Root is attached to the tree.
+func (s *E[T]) UnmarshalJSON(buf []byte) error {
+	t := new(jsintree)
+	if err := json.Unmarshal(buf, t); err != nil {
+		return err
+	}
+	s.Names = t.Names
+	s.Map = t.Map
+	if len(s.Map) == 0 && len(s.Names) > 0 {
+		s.Map = types.Map{}
+		for n, v := range s.Names {
+			s.Map[v] = n
+		}
+	}
+	s.Root = nil
+	if len(t.Root) == 0 || string(t.Root) == "null" {
+		return nil
+	}
+	s.Root = new(Node[T])
+	s.Root.Tree = s
+	return s.Root.unmarshal(t.Root, s.Map)
+}
+
 // JSONL implements rwxrob/json.AsJSON.
 func (s *E[T]) JSON() ([]byte, error) { return json.Marshal(s) }
 
-// JSONL implements rwxrob/json.AsJSON.
+// JSONL implements rwxrob/json.AsJSON using the long form (see
+// MarshalJSONLong) with indentation.
 func (s *E[T]) JSONL() ([]byte, error) {
-	return json.MarshalIndent(s, "  ", "  ")
+	return json.MarshalIndent(s.long(), "  ", "  ")
 }
 
 // String implements rwxrob/json.Stringer and fmt.Stringer.
@@ -6,7 +6,7 @@ import (
 	"github.com/rwxrob/structs/tree"
 )
 
-func ExampleTree() {
+func ExampleE() {
 	t := tree.New[any]("foo")
 	t.Print()
 	t.Root.Print()
@@ -17,7 +17,7 @@ func ExampleTree() {
 	// ["UNKNOWN","foo"]
 }
 
-func ExampleTree_Node() {
+func ExampleE_Node() {
 	t := tree.New[any]("foo")
 	n := t.Node(10, "")
 	n.Print()
@@ -29,3 +29,88 @@ func ExampleTree_Node() {
 	// {"Names":["UNKNOWN","foo"],"Map":{"UNKNOWN":0,"foo":1},"Root":{"T":1}}
 	// {"T":1,"N":[{"T":10,"V":""}]}
 }
+
+func ExampleE_UnmarshalJSON() {
+	t := tree.New[any]("foo", "bar")
+	t.Root.Add(2, "some").Add(2, "thing")
+	buf, _ := t.JSON()
+
+	u := new(tree.E[any])
+	if err := u.UnmarshalJSON(buf); err != nil {
+		fmt.Println(err)
+	}
+	u.Print()
+	fmt.Println(u.String() == t.String())
+
+	// tree back-reference and links restored
+	n := u.Root.Nodes()[0].Nodes()[0]
+	fmt.Println(n.Tree == u, n.P.P == u.Root, u.Root.Count)
+
+	// Output:
+	// {"Names":["UNKNOWN","foo","bar"],"Map":{"UNKNOWN":0,"bar":2,"foo":1},"Root":{"T":1,"N":[{"T":2,"V":"some","N":[{"T":2,"V":"thing"}]}]}}
+	// true
+	// true true 1
+}
+
+func ExampleE_MarshalJSONLong() {
+	t := tree.New[any]("Document", "Section")
+	s := t.Root.Add(2, nil)
+	s.Add(2, "one")
+	s.Add(9, "no name")
+	buf, _ := t.MarshalJSONLong()
+	fmt.Println(string(buf))
+	// Output:
+	// {"Names":["UNKNOWN","Document","Section"],"Root":{"T":"Document","N":[{"T":"Section","N":[{"T":"Section","V":"one"},{"T":9,"V":"no name"}]}]}}
+}
+
+func ExampleE_PrintLong() {
+	t := tree.New[any]("Document", "Section")
+	t.Root.Add(2, "one")
+	t.PrintLong()
+	// Output:
+	// {
+	//     "Names": [
+	//       "UNKNOWN",
+	//       "Document",
+	//       "Section"
+	//     ],
+	//     "Root": {
+	//       "T": "Document",
+	//       "N": [
+	//         {
+	//           "T": "Section",
+	//           "V": "one"
+	//         }
+	//       ]
+	//     }
+	//   }
+}
+
+func ExampleE_UnmarshalJSON_long() {
+	t := tree.New[any]("Document", "Section")
+	t.Root.Add(2, "one").Add(2, "two")
+	buf, _ := t.MarshalJSONLong()
+
+	u := new(tree.E[any])
+	if err := u.UnmarshalJSON(buf); err != nil {
+		fmt.Println(err)
+	}
+	u.Print()
+	fmt.Println(u.String() == t.String())
+
+	// mixed forms are fine as well
+	err := u.UnmarshalJSON([]byte(`{"Names":["UNKNOWN","Document"],"Root":{"T":"Document","N":[{"T":3}]}}`))
+	fmt.Println(err)
+	u.Root.Print()
+
+	// but names must exist
+	err = u.UnmarshalJSON([]byte(`{"Names":["UNKNOWN","Document"],"Root":{"T":"Nope"}}`))
+	fmt.Println(err)
+
+	// Output:
+	// {"Names":["UNKNOWN","Document","Section"],"Map":{"Document":1,"Section":2,"UNKNOWN":0},"Root":{"T":1,"N":[{"T":2,"V":"one","N":[{"T":2,"V":"two"}]}]}}
+	// true
+	// <nil>
+	// {"T":1,"N":[{"T":3}]}
+	// unknown type name: "Nope"
+}
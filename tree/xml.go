@@ -0,0 +1,174 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rwxrob/structs/types"
+)
+
+// XMLText is the type name given to character data by FromXML (and
+// recognized by ToXML). Attributes are given the type name of the
+// attribute with an @ prefix (ex: @href).
+const XMLText = "#text"
+
+// FromXML decodes an XML (or XHTML) stream into a new tree. Every
+// element becomes a Node with its name (including any namespace prefix)
+// as its type name. These are added to the Types in the order in which
+// they are first found (so the Root has type 1 as usual). Attributes
+// become nodes (with the attribute value) under the element in order
+// before any others with their name prefixed by @ (ex: @href) as
+// their type name. Character data that is not only white space becomes
+// an XMLText Node with the data as its value. Comments, processing
+// instructions, and directives are dropped.
+func FromXML(r io.Reader) (*E[string], error) {
+	t := New[string]()
+	var names []string
+	index := map[string]int{}
+	typeof := func(name string) int {
+		if i, has := index[name]; has {
+			return i
+		}
+		names = append(names, name)
+		index[name] = len(names)
+		return len(names)
+	}
+
+	dec := xml.NewDecoder(r)
+	var cur *Node[string]
+	var done bool
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return t, err
+		}
+		switch v := tok.(type) {
+
+		case xml.StartElement:
+			if done {
+				return t, fmt.Errorf("more than one root element: %v", xmlName(v.Name))
+			}
+			typ := typeof(xmlName(v.Name))
+			n := t.Root
+			if cur == nil {
+				n.T = typ
+			} else {
+				n = cur.Add(typ, "")
+			}
+			for _, a := range v.Attr {
+				n.Add(typeof("@"+xmlName(a.Name)), a.Value)
+			}
+			cur = n
+
+		case xml.EndElement:
+			if cur == nil || names[cur.T-1] != xmlName(v.Name) {
+				return t, fmt.Errorf("unexpected end element: %v", xmlName(v.Name))
+			}
+			if cur.P == nil {
+				done = true
+			}
+			cur = cur.P
+
+		case xml.CharData:
+			if cur == nil || len(strings.TrimSpace(string(v))) == 0 {
+				continue
+			}
+			cur.Add(typeof(XMLText), string(v))
+		}
+	}
+	if !done {
+		return t, io.ErrUnexpectedEOF
+	}
+	t.Types.Set(names...)
+	return t, nil
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// ToXML encodes the Node (and every Node under it) as XML using the
+// names (ex: Tree.Names) to name each element from its type. If names
+// is nil those from the Tree of the Node are used. Nodes with a type
+// name beginning with @ become attributes of the element above them
+// and XMLText nodes become character data (see FromXML). Any other
+// value is written as character data at the start of its element.
+func ToXML[T any](w io.Writer, n *Node[T], names types.Names) error {
+	if names == nil && n.Tree != nil {
+		names = n.Tree.Names
+	}
+	nameof := func(c *Node[T]) (string, error) {
+		if c.T < 0 || c.T >= len(names) {
+			return "", fmt.Errorf("no name for type: %v", c.T)
+		}
+		return names[c.T], nil
+	}
+	enc := xml.NewEncoder(w)
+	var err error
+
+	enter := func(c *Node[T], _ int) Flow {
+		var name string
+		if name, err = nameof(c); err != nil {
+			return Stop
+		}
+		switch {
+		case name == XMLText:
+			if err = enc.EncodeToken(xml.CharData(valstr(c.V))); err != nil {
+				return Stop
+			}
+			return Skip
+		case strings.HasPrefix(name, "@"):
+			return Skip
+		}
+		start := xml.StartElement{Name: xml.Name{Local: name}}
+		for a := c.first; a != nil; a = a.right {
+			var aname string
+			if aname, err = nameof(a); err != nil {
+				return Stop
+			}
+			if strings.HasPrefix(aname, "@") {
+				start.Attr = append(start.Attr, xml.Attr{
+					Name:  xml.Name{Local: aname[1:]},
+					Value: valstr(a.V),
+				})
+			}
+		}
+		if err = enc.EncodeToken(start); err != nil {
+			return Stop
+		}
+		if hasValue(c.V) {
+			if err = enc.EncodeToken(xml.CharData(valstr(c.V))); err != nil {
+				return Stop
+			}
+		}
+		return Continue
+	}
+
+	leave := func(c *Node[T], _ int) Flow {
+		name, _ := nameof(c)
+		if name == XMLText || strings.HasPrefix(name, "@") {
+			return Continue
+		}
+		if err = enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return Stop
+		}
+		return Continue
+	}
+
+	n.WalkDeepFlow(enter, leave)
+	if err != nil {
+		return err
+	}
+	return enc.Flush()
+}
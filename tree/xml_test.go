@@ -0,0 +1,83 @@
+package tree_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rwxrob/structs/tree"
+	"github.com/rwxrob/structs/types"
+)
+
+func ExampleFromXML() {
+	in := `<?xml version="1.0"?>
+<!-- dropped -->
+<html xmlns:x="urn:x">
+  <body class="main" id="top">
+    <p>Hello <b>there</b> &amp; welcome</p>
+    <x:note/>
+  </body>
+</html>`
+	t, err := tree.FromXML(strings.NewReader(in))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(t.Names)
+	fmt.Print(t.Render(nil))
+
+	// and back again
+	tree.ToXML(os.Stdout, t.Root, nil)
+	fmt.Println()
+
+	// Output:
+	// ["UNKNOWN","html","@xmlns:x","body","@class","@id","p","#text","b","x:note"]
+	// html
+	// ├── @xmlns:x "urn:x"
+	// └── body
+	//     ├── @class "main"
+	//     ├── @id "top"
+	//     ├── p
+	//     │   ├── #text "Hello "
+	//     │   ├── b
+	//     │   │   └── #text "there"
+	//     │   └── #text " & welcome"
+	//     └── x:note
+	// <html xmlns:x="urn:x"><body class="main" id="top"><p>Hello <b>there</b> &amp; welcome</p><x:note></x:note></body></html>
+}
+
+func ExampleFromXML_errors() {
+	for _, in := range []string{`<a>`, `<a></b>`, `<a/><b/>`, `text`} {
+		_, err := tree.FromXML(strings.NewReader(in))
+		fmt.Println(err)
+	}
+	// Output:
+	// unexpected EOF
+	// unexpected end element: b
+	// more than one root element: b
+	// unexpected EOF
+}
+
+func ExampleToXML() {
+	t := tree.New[int]("Doc", "Item", "@n")
+	i := t.Root.Add(2, 42)
+	i.Add(3, 1)
+	t.Root.Add(2, 0)
+
+	// names from the tree
+	tree.ToXML(os.Stdout, t.Root, nil)
+	fmt.Println()
+
+	// other names
+	names := types.Names{"UNKNOWN", "list", "li", "@num"}
+	tree.ToXML(os.Stdout, t.Root, names)
+	fmt.Println()
+
+	// every type needs a name
+	t.Root.Add(9, 0)
+	fmt.Println(tree.ToXML(os.Stdout, t.Root, names))
+
+	// Output:
+	// <Doc><Item n="1">42</Item><Item></Item></Doc>
+	// <list><li num="1">42</li><li></li></list>
+	// no name for type: 9
+}